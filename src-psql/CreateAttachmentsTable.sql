@@ -0,0 +1,9 @@
+CREATE TABLE attachments (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "taskId" VARCHAR(20) NOT NULL,
+    uploader VARCHAR(20) NOT NULL,
+    filename TEXT NOT NULL,
+    "mimeType" TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    "createdAt" BIGINT NOT NULL
+);
\ No newline at end of file
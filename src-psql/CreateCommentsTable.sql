@@ -0,0 +1,10 @@
+CREATE TABLE comments (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "taskId" VARCHAR(20) NOT NULL,
+    author VARCHAR(20) NOT NULL,
+    body TEXT NOT NULL,
+    "createdAt" BIGINT NOT NULL,
+    "updatedAt" BIGINT NOT NULL
+);
+
+CREATE INDEX comments_search_index ON comments USING GIN (to_tsvector('english', body));
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE filters (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    owner VARCHAR(20) NOT NULL,
+    name VARCHAR(40) NOT NULL,
+    query TEXT NOT NULL
+);
\ No newline at end of file
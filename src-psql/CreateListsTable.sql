@@ -3,5 +3,12 @@ CREATE TABLE lists (
     name VARCHAR(20) NOT NULL,
     owner VARCHAR(20) NOT NULL,
     private BOOLEAN NOT NULL,
-    members VARCHAR(20)[] NOT NULL
-);
\ No newline at end of file
+    members VARCHAR(20)[] NOT NULL,
+    "deletedAt" BIGINT NOT NULL,
+    "deletedBy" VARCHAR(20) NOT NULL,
+    "archivedAt" BIGINT NOT NULL,
+    "autoArchiveDays" INTEGER NOT NULL,
+    version INTEGER NOT NULL
+);
+
+CREATE INDEX lists_search_index ON lists USING GIN (to_tsvector('english', name));
\ No newline at end of file
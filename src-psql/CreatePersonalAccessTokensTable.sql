@@ -0,0 +1,12 @@
+CREATE TABLE personal_access_tokens (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "userId" VARCHAR(20) NOT NULL,
+    name VARCHAR(60) NOT NULL,
+    "tokenHash" VARCHAR(64) NOT NULL UNIQUE,
+    scopes VARCHAR(20)[] NOT NULL,
+    "createdAt" BIGINT NOT NULL,
+    "expiresAt" BIGINT NOT NULL,
+    "lastUsedAt" BIGINT NOT NULL
+);
+
+CREATE INDEX personal_access_tokens_user_index ON personal_access_tokens ("userId");
\ No newline at end of file
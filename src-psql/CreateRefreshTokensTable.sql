@@ -0,0 +1,12 @@
+CREATE TABLE refresh_tokens (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "userId" VARCHAR(20) NOT NULL,
+    "sessionId" VARCHAR(20) NOT NULL,
+    "tokenHash" VARCHAR(64) NOT NULL UNIQUE,
+    "createdAt" BIGINT NOT NULL,
+    "expiresAt" BIGINT NOT NULL,
+    "revokedAt" BIGINT NOT NULL
+);
+
+CREATE INDEX refresh_tokens_user_index ON refresh_tokens ("userId");
+CREATE INDEX refresh_tokens_session_index ON refresh_tokens ("sessionId");
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE sessions (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "userId" VARCHAR(20) NOT NULL,
+    device VARCHAR(60) NOT NULL,
+    "userAgent" VARCHAR(512) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    "createdAt" BIGINT NOT NULL,
+    "lastSeenAt" BIGINT NOT NULL,
+    "revokedAt" BIGINT NOT NULL
+);
+
+CREATE INDEX sessions_user_index ON sessions ("userId");
\ No newline at end of file
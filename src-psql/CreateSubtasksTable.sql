@@ -0,0 +1,8 @@
+CREATE TABLE subtasks (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "taskId" VARCHAR(20) NOT NULL,
+    "parentId" VARCHAR(20) REFERENCES subtasks (id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    "listOrder" INTEGER NOT NULL,
+    completed BOOLEAN NOT NULL
+);
\ No newline at end of file
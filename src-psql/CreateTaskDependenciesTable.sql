@@ -0,0 +1,5 @@
+CREATE TABLE task_dependencies (
+    "taskId" VARCHAR(20) NOT NULL,
+    "blockedBy" VARCHAR(20) NOT NULL,
+    PRIMARY KEY ("taskId", "blockedBy")
+);
\ No newline at end of file
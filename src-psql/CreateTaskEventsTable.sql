@@ -0,0 +1,12 @@
+CREATE TABLE task_events (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "taskId" VARCHAR(20) NOT NULL,
+    "listId" VARCHAR(20) NOT NULL,
+    actor VARCHAR(20) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    timestamp BIGINT NOT NULL,
+    changes JSONB NOT NULL,
+    sequence BIGSERIAL NOT NULL
+);
+
+CREATE INDEX task_events_task_index ON task_events ("taskId", sequence);
\ No newline at end of file
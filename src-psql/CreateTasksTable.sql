@@ -1,13 +1,31 @@
+CREATE FUNCTION tags_to_text(tags VARCHAR(60)[]) RETURNS TEXT AS $$
+    SELECT replace(array_to_string(tags, ' '), '/', ' ')
+$$ LANGUAGE SQL IMMUTABLE;
+
 CREATE TABLE tasks (
     id VARCHAR(20) NOT NULL PRIMARY KEY,
     owner VARCHAR(20) NOT NULL,
     title TEXT NOT NULL,
-    tags VARCHAR(20)[] NOT NULL,
+    tags VARCHAR(60)[] NOT NULL,
     "listId" VARCHAR(20) NOT NULL,
-    "listOrder" INTEGER NOT NULL,
+    rank TEXT COLLATE "C" NOT NULL,
     priority SMALLINT NOT NULL,
     due BIGINT NOT NULL,
     "plannedStart" BIGINT NOT NULL,
     "plannedEnd" BIGINT NOT NULL,
-    completed BOOLEAN NOT NULL
-);
\ No newline at end of file
+    completed BOOLEAN NOT NULL,
+    recurrence TEXT NOT NULL,
+    assignees VARCHAR(20)[] NOT NULL,
+    description TEXT NOT NULL,
+    "deletedAt" BIGINT NOT NULL,
+    "deletedBy" VARCHAR(20) NOT NULL,
+    "archivedAt" BIGINT NOT NULL,
+    "createdAt" BIGINT NOT NULL,
+    "updatedAt" BIGINT NOT NULL,
+    "completedAt" BIGINT NOT NULL,
+    version INTEGER NOT NULL
+);
+
+CREATE INDEX tasks_search_index ON tasks USING GIN ((setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', tags_to_text(tags)), 'A') || setweight(to_tsvector('english', description), 'B')));
+
+CREATE INDEX tasks_rank_index ON tasks ("listId", rank);
\ No newline at end of file
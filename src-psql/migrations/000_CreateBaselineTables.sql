@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS lists (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    name VARCHAR(20) NOT NULL,
+    owner VARCHAR(20) NOT NULL,
+    private BOOLEAN NOT NULL,
+    members VARCHAR(20)[] NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    username VARCHAR(20) NOT NULL UNIQUE,
+    password TEXT NOT NULL,
+    friends VARCHAR(20)[] NOT NULL,
+    outgoing_req VARCHAR(20)[] NOT NULL,
+    incoming_req VARCHAR(20)[] NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    owner VARCHAR(20) NOT NULL,
+    title TEXT NOT NULL,
+    tags VARCHAR(20)[] NOT NULL,
+    "listId" VARCHAR(20) NOT NULL,
+    "listOrder" INTEGER NOT NULL,
+    priority SMALLINT NOT NULL,
+    due BIGINT NOT NULL,
+    "plannedStart" BIGINT NOT NULL,
+    "plannedEnd" BIGINT NOT NULL,
+    completed BOOLEAN NOT NULL
+);
\ No newline at end of file
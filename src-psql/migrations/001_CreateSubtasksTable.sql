@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS subtasks (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "taskId" VARCHAR(20) NOT NULL,
+    "parentId" VARCHAR(20) REFERENCES subtasks (id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    "listOrder" INTEGER NOT NULL,
+    completed BOOLEAN NOT NULL
+);
+
+ALTER TABLE subtasks ADD COLUMN IF NOT EXISTS "parentId" VARCHAR(20) REFERENCES subtasks (id) ON DELETE CASCADE;
\ No newline at end of file
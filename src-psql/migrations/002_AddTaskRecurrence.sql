@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ALTER COLUMN recurrence DROP DEFAULT;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignees VARCHAR(20)[] NOT NULL DEFAULT '{}';
+ALTER TABLE tasks ALTER COLUMN assignees DROP DEFAULT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "taskId" VARCHAR(20) NOT NULL,
+    author VARCHAR(20) NOT NULL,
+    body TEXT NOT NULL,
+    "createdAt" BIGINT NOT NULL,
+    "updatedAt" BIGINT NOT NULL
+);
\ No newline at end of file
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS task_events (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "taskId" VARCHAR(20) NOT NULL,
+    "listId" VARCHAR(20) NOT NULL,
+    actor VARCHAR(20) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    timestamp BIGINT NOT NULL,
+    changes JSONB NOT NULL,
+    sequence BIGSERIAL NOT NULL
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'task_events' AND column_name = 'sequence') THEN
+        ALTER TABLE task_events ADD COLUMN sequence BIGSERIAL NOT NULL;
+
+        -- Events recorded before the sequence existed are numbered in the
+        -- order of their timestamps.
+        UPDATE task_events SET sequence = ordered.sequence
+        FROM (SELECT id, row_number() OVER (ORDER BY timestamp, id) AS sequence FROM task_events) ordered
+        WHERE task_events.id = ordered.id;
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS task_events_task_index ON task_events ("taskId", sequence);
\ No newline at end of file
@@ -0,0 +1,11 @@
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS "deletedAt" BIGINT NOT NULL DEFAULT -1;
+ALTER TABLE lists ALTER COLUMN "deletedAt" DROP DEFAULT;
+
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS "deletedBy" VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE lists ALTER COLUMN "deletedBy" DROP DEFAULT;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS "deletedAt" BIGINT NOT NULL DEFAULT -1;
+ALTER TABLE tasks ALTER COLUMN "deletedAt" DROP DEFAULT;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS "deletedBy" VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE tasks ALTER COLUMN "deletedBy" DROP DEFAULT;
\ No newline at end of file
@@ -0,0 +1,8 @@
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS "archivedAt" BIGINT NOT NULL DEFAULT -1;
+ALTER TABLE lists ALTER COLUMN "archivedAt" DROP DEFAULT;
+
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS "autoArchiveDays" INTEGER NOT NULL DEFAULT -1;
+ALTER TABLE lists ALTER COLUMN "autoArchiveDays" DROP DEFAULT;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS "archivedAt" BIGINT NOT NULL DEFAULT -1;
+ALTER TABLE tasks ALTER COLUMN "archivedAt" DROP DEFAULT;
\ No newline at end of file
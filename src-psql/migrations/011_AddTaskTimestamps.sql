@@ -0,0 +1,12 @@
+-- Existing tasks are taken to have been created, and completed if they are,
+-- when the migration runs.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS "createdAt" BIGINT NOT NULL DEFAULT extract(epoch FROM now())::BIGINT;
+ALTER TABLE tasks ALTER COLUMN "createdAt" DROP DEFAULT;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS "updatedAt" BIGINT NOT NULL DEFAULT extract(epoch FROM now())::BIGINT;
+ALTER TABLE tasks ALTER COLUMN "updatedAt" DROP DEFAULT;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS "completedAt" BIGINT NOT NULL DEFAULT -1;
+ALTER TABLE tasks ALTER COLUMN "completedAt" DROP DEFAULT;
+
+UPDATE tasks SET "completedAt" = "updatedAt" WHERE completed AND "completedAt" = -1;
\ No newline at end of file
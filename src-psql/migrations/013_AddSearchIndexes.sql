@@ -0,0 +1,16 @@
+-- Left alone once it exists, as nested tags replace it later on.
+DO $$
+BEGIN
+    IF to_regproc('tags_to_text') IS NULL THEN
+        CREATE FUNCTION tags_to_text(tags VARCHAR(20)[]) RETURNS TEXT AS $function$
+            SELECT array_to_string(tags, ' ')
+        $function$ LANGUAGE SQL IMMUTABLE;
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS tasks_search_index ON tasks USING GIN ((setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', tags_to_text(tags)), 'A') || setweight(to_tsvector('english', description), 'B')));
+
+CREATE INDEX IF NOT EXISTS comments_search_index ON comments USING GIN (to_tsvector('english', body));
+
+CREATE INDEX IF NOT EXISTS lists_search_index ON lists USING GIN (to_tsvector('english', name));
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tags (
+    "listId" VARCHAR(20) NOT NULL,
+    name VARCHAR(20) NOT NULL,
+    color VARCHAR(7) NOT NULL,
+    description VARCHAR(200) NOT NULL,
+    PRIMARY KEY ("listId", name)
+);
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- Nested tags take up more room than flat ones.
+ALTER TABLE tags ALTER COLUMN name TYPE VARCHAR(60);
+ALTER TABLE tasks ALTER COLUMN tags TYPE VARCHAR(60)[];
+
+-- Each part of a nested tag is searched as a word of its own. The search index
+-- is rebuilt, as it does not follow the new definition by itself.
+CREATE OR REPLACE FUNCTION tags_to_text(tags VARCHAR(60)[]) RETURNS TEXT AS $$
+    SELECT replace(array_to_string(tags, ' '), '/', ' ')
+$$ LANGUAGE SQL IMMUTABLE;
+
+REINDEX INDEX tasks_search_index;
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- Tasks keep their order: every listOrder becomes a rank of four base-36
+-- digits, followed by a digit other than the smallest so that there is room
+-- in front of it. The rebalance job respreads them later on.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'tasks' AND column_name = 'listOrder') THEN
+        ALTER TABLE tasks ADD COLUMN rank TEXT COLLATE "C";
+
+        UPDATE tasks SET rank =
+            substr('0123456789abcdefghijklmnopqrstuvwxyz', "listOrder" / 46656 % 36 + 1, 1) ||
+            substr('0123456789abcdefghijklmnopqrstuvwxyz', "listOrder" / 1296 % 36 + 1, 1) ||
+            substr('0123456789abcdefghijklmnopqrstuvwxyz', "listOrder" / 36 % 36 + 1, 1) ||
+            substr('0123456789abcdefghijklmnopqrstuvwxyz', "listOrder" % 36 + 1, 1) ||
+            'i';
+
+        ALTER TABLE tasks ALTER COLUMN rank SET NOT NULL;
+        ALTER TABLE tasks DROP COLUMN "listOrder";
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS tasks_rank_index ON tasks ("listId", rank);
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE lists ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE lists ALTER COLUMN version DROP DEFAULT;
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tasks ALTER COLUMN version DROP DEFAULT;
\ No newline at end of file
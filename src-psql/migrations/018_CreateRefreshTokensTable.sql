@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "userId" VARCHAR(20) NOT NULL,
+    "familyId" VARCHAR(20) NOT NULL,
+    "tokenHash" VARCHAR(64) NOT NULL UNIQUE,
+    "createdAt" BIGINT NOT NULL,
+    "expiresAt" BIGINT NOT NULL,
+    "revokedAt" BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_index ON refresh_tokens ("userId");
+-- Sessions take the place of token families later on.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'refresh_tokens' AND column_name = 'familyId') THEN
+        CREATE INDEX IF NOT EXISTS refresh_tokens_family_index ON refresh_tokens ("familyId");
+    END IF;
+END
+$$;
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(20) NOT NULL PRIMARY KEY,
+    "userId" VARCHAR(20) NOT NULL,
+    device VARCHAR(60) NOT NULL,
+    "userAgent" VARCHAR(512) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    "createdAt" BIGINT NOT NULL,
+    "lastSeenAt" BIGINT NOT NULL,
+    "revokedAt" BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_index ON sessions ("userId");
+
+-- Refresh tokens now belong to a session instead of a token family. Tokens
+-- issued before sessions existed belong to none, so they are dropped and
+-- their users log in again.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'refresh_tokens' AND column_name = 'familyId') THEN
+        DELETE FROM refresh_tokens;
+        DROP INDEX IF EXISTS refresh_tokens_family_index;
+        ALTER TABLE refresh_tokens RENAME COLUMN "familyId" TO "sessionId";
+    END IF;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_session_index ON refresh_tokens ("sessionId");
\ No newline at end of file
@@ -2,6 +2,40 @@ import axios from "axios";
 
 const BASE_URL = "http://localhost:8080"
 
+let refreshAccessToken: (() => Promise<string | null>) | null = null
+let pendingRefresh: Promise<string | null> | null = null
+const retriedRequests = new WeakSet<object>()
+
+// Access tokens are short-lived. Requests rejected because theirs expired are
+// retried once with a new one, and concurrent ones share a single refresh as
+// each refresh token can only be used once.
+export function onAccessTokenExpired(refresher: () => Promise<string | null>) {
+    refreshAccessToken = refresher
+}
+
+axios.interceptors.response.use(response => response, async error => {
+    const config = error.config
+    if (error.response?.status !== 401 || !config?.headers?.Authorization || retriedRequests.has(config) || !refreshAccessToken) {
+        throw error
+    }
+
+    if (!pendingRefresh) {
+        pendingRefresh = refreshAccessToken().finally(() => {
+            pendingRefresh = null
+        })
+    }
+
+    const token = await pendingRefresh
+    if (!token) {
+        throw error
+    }
+
+    retriedRequests.add(config)
+    config.headers.Authorization = `Bearer ${token}`
+
+    return axios(config)
+})
+
 export function sendGet(route: string, params?: object, headers?: object) {
     return axios.get(`${BASE_URL}${route}`, {
         params: params,
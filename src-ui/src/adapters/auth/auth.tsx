@@ -1,4 +1,4 @@
-import { sendGet, sendPost } from "../adapter";
+import { sendPost } from "../adapter";
 
 export function createUser(username: string, password: string) {
     let body = {
@@ -10,10 +10,27 @@ export function createUser(username: string, password: string) {
 }
 
 export async function authenticate(username: string, password: string) {
-    let params = {
+    let body = {
         "username": username,
         "password": password
     }
 
-    return sendGet("/user/authenticate", params)
+    return sendPost("/auth/login", body)
+}
+
+export function refreshTokens(refreshToken: string) {
+    let body = {
+        "refreshToken": refreshToken
+    }
+
+    return sendPost("/user/refresh", body)
+}
+
+export function revokeTokens(refreshToken: string, everywhere: boolean) {
+    let body = {
+        "refreshToken": refreshToken,
+        "everywhere": everywhere
+    }
+
+    return sendPost("/user/logout", body)
 }
\ No newline at end of file
@@ -4,8 +4,7 @@ import { sendDelete, sendGet, sendPost } from "../adapter";
 
 export function createList(authData: AuthData, name: string, ownerId: string, isPrivate: boolean) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let body = {
@@ -20,7 +19,7 @@ export function createList(authData: AuthData, name: string, ownerId: string, is
 export function editExistingList(data: EditListRequest) {
     let headers = {
         "Authorization": `Bearer ${data.authData.token}`,
-        "id": data.authData.id
+        "If-Match": `"${data.version}"`
     }
 
     let body = {
@@ -35,7 +34,7 @@ export function editExistingList(data: EditListRequest) {
 export function editExistingListMembers(data: EditListMembersRequest) {
     let headers = {
         "Authorization": `Bearer ${data.authData.token}`,
-        "id": data.authData.id
+        "If-Match": `"${data.version}"`
     }
 
     let body = {
@@ -48,8 +47,7 @@ export function editExistingListMembers(data: EditListMembersRequest) {
 
 export function deleteExistingList(authData: AuthData, id: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -61,8 +59,7 @@ export function deleteExistingList(authData: AuthData, id: string) {
 
 export function fetchListsByUserId(authData: AuthData, userId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -74,8 +71,7 @@ export function fetchListsByUserId(authData: AuthData, userId: string) {
 
 export function fetchListMembers(authData: AuthData, listId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -87,8 +83,7 @@ export function fetchListMembers(authData: AuthData, listId: string) {
 
 export function fetchListOwner(authData: AuthData, listId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -4,8 +4,7 @@ import { sendDelete, sendGet, sendPost } from "../adapter";
 
 export function createTask(data: CreateTaskRequest) {
     let headers = {
-        "Authorization": `Bearer ${data.authData.token}`,
-        "id": data.authData.id
+        "Authorization": `Bearer ${data.authData.token}`
     }
 
     let body = {
@@ -25,7 +24,7 @@ export function createTask(data: CreateTaskRequest) {
 export function editExistingTask(data: EditTaskRequest) {
     let headers = {
         "Authorization": `Bearer ${data.authData.token}`,
-        "id": data.authData.id
+        "If-Match": `"${data.version}"`
     }
 
     let body = {
@@ -45,13 +44,14 @@ export function editExistingTask(data: EditTaskRequest) {
 export function editExistingTaskCompleted(data: EditTaskCompletedRequest) {
     let headers = {
         "Authorization": `Bearer ${data.authData.token}`,
-        "id": data.authData.id
+        "If-Match": `"${data.version}"`
     }
 
     let body = {
         "id": data.id,
         "listId": data.listId,
-        "completed": data.completed
+        "completed": data.completed,
+        "timezone": Intl.DateTimeFormat().resolvedOptions().timeZone
     }
 
     return sendPost("/task/editCompleted", body, headers)
@@ -59,8 +59,7 @@ export function editExistingTaskCompleted(data: EditTaskCompletedRequest) {
 
 export function deleteExistingTask(authData: AuthData, id: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -72,8 +71,7 @@ export function deleteExistingTask(authData: AuthData, id: string) {
 
 export function fetchTasks(authData: AuthData, listId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -85,8 +83,7 @@ export function fetchTasks(authData: AuthData, listId: string) {
 
 export function fetchTagsByListId(authData: AuthData, listId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -98,8 +95,7 @@ export function fetchTagsByListId(authData: AuthData, listId: string) {
 
 export function reorderList(authData: AuthData, id: string, listId: string, newListOrder: number) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let body = {
@@ -111,10 +107,10 @@ export function reorderList(authData: AuthData, id: string, listId: string, newL
     return sendPost("/task/reorder", body, headers)
 }
 
-export function moveTaskToAnotherList(authData: AuthData, id: string, originalListId: string, newListId: string) {
+export function moveTaskToAnotherList(authData: AuthData, id: string, originalListId: string, newListId: string, version: number) {
     let headers = {
         "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "If-Match": `"${version}"`
     }
 
     let body = {
@@ -3,8 +3,7 @@ import { sendDelete, sendGet, sendPost } from "../adapter";
 
 export function fetchUserInfo(authData: AuthData, userId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     return sendGet(`/user/${userId}`, undefined, headers)
@@ -12,8 +11,7 @@ export function fetchUserInfo(authData: AuthData, userId: string) {
 
 export function fetchUserByUsername(authData: AuthData, username: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -25,8 +23,7 @@ export function fetchUserByUsername(authData: AuthData, username: string) {
 
 export function fetchUserFriends(authData: AuthData) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     return sendGet(`/user/friend/all`, undefined, headers)
@@ -34,8 +31,7 @@ export function fetchUserFriends(authData: AuthData) {
 
 export function sendOutgoingFriendRequest(authData: AuthData, userId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let body = {
@@ -46,8 +42,7 @@ export function sendOutgoingFriendRequest(authData: AuthData, userId: string) {
 }
 export function deleteFriendRequest(authData: AuthData, userId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -59,8 +54,7 @@ export function deleteFriendRequest(authData: AuthData, userId: string) {
 
 export function acceptIncomingFriendRequest(authData: AuthData, userId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let body = {
@@ -72,8 +66,7 @@ export function acceptIncomingFriendRequest(authData: AuthData, userId: string)
 
 export function deleteFriend(authData: AuthData, userId: string) {
     let headers = {
-        "Authorization": `Bearer ${authData.token}`,
-        "id": authData.id
+        "Authorization": `Bearer ${authData.token}`
     }
 
     let params = {
@@ -1,11 +1,12 @@
 import { TaskState } from './../services/task/taskSplice';
 import { AuthState } from './../services/auth/authSplice';
 import { configureStore, ThunkAction, Action } from '@reduxjs/toolkit';
-import authReducer from '../services/auth/authSplice';
+import authReducer, { refresh } from '../services/auth/authSplice';
 import taskReducer from '../services/task/taskSplice';
 import tagReducer from '../services/task/tagSplice';
 import userReducer from '../services/user/userSplice';
 import listReducer from '../services/list/listSplice';
+import { onAccessTokenExpired } from '../adapters/adapter';
 
 interface StoreState {
     auth: AuthState,
@@ -16,6 +17,7 @@ interface AuthStateToStore {
     authenticated: boolean
     id: string | null
     token: string | null
+    refreshToken: string | null
 }
 
 const saveToLocalStorage = (state: StoreState) => {
@@ -23,7 +25,8 @@ const saveToLocalStorage = (state: StoreState) => {
         let authStateToStore: AuthStateToStore = {
             authenticated: state.auth.authenticated,
             id: state.auth.id,
-            token: state.auth.token
+            token: state.auth.token,
+            refreshToken: state.auth.refreshToken
         }
         localStorage.setItem('auth', JSON.stringify(authStateToStore))
     } catch (e) {
@@ -59,6 +62,20 @@ store.subscribe(() => {
     saveToLocalStorage(store.getState())
 })
 
+onAccessTokenExpired(async () => {
+    const refreshToken = store.getState().auth.refreshToken
+    if (!refreshToken) {
+        return null
+    }
+
+    const result = await store.dispatch(refresh(refreshToken))
+    if (refresh.fulfilled.match(result) && result.payload.success) {
+        return result.payload.data.token as string
+    }
+
+    return null
+})
+
 export type AppDispatch = typeof store.dispatch
 export type RootState = ReturnType<typeof store.getState>
 export type AppThunk<ReturnType = void> = ThunkAction<
@@ -10,6 +10,7 @@ import { EditListMembersRequest, RetrieveListMembersRequest } from "../../interf
 export interface ListMembersDialogProps {
     open: boolean
     listId: string
+    listVersion: number
     authData: AuthData
     onClose: () => void
 }
@@ -20,7 +21,7 @@ const DEFAULT_MEMBER_SUGGESTIONS_OPEN_VALUE = false
 
 export function ListMembersDialog(props: ListMembersDialogProps) {
     const dispatch = useAppDispatch()
-    const { open, listId, authData, onClose } = props
+    const { open, listId, listVersion, authData, onClose } = props
 
     const [memberSuggestions, setMemberSuggestions] = useState<Array<UserFriend>>(DEFAULT_MEMBER_SUGGESTIONS_VALUE)
     const [memberSuggestionsOpen, setMemberSuggestionsOpen] = useState<boolean>(DEFAULT_MEMBER_SUGGESTIONS_OPEN_VALUE)
@@ -62,7 +63,8 @@ export function ListMembersDialog(props: ListMembersDialogProps) {
         let editListMembersRequest: EditListMembersRequest = {
             authData: authData,
             id: listId,
-            members: membersSelected.map((member, _, __) => member.id)
+            members: membersSelected.map((member, _, __) => member.id),
+            version: listVersion
         }
 
         dispatch(editListMembers(editListMembersRequest)).then(_ => {
@@ -65,7 +65,8 @@ export function ListSettingsDialog(props: ListSettingsDialogProps) {
                 authData: authData,
                 id: data.id,
                 name: listName,
-                private: isPrivate
+                private: isPrivate,
+                version: data.version
             }
 
             dispatch(editList(editListRequest)).then(value => {
@@ -222,7 +222,7 @@ export function ListSelect(props: ListSelectProps) {
                 </Select>
             </FormControl>
 
-            {listMembersDialogOpen && listBeingEdited && <ListMembersDialog listId={listBeingEdited?.id} open={listMembersDialogOpen} authData={authData} onClose={handleListMembersDialogClose} />}
+            {listMembersDialogOpen && listBeingEdited && <ListMembersDialog listId={listBeingEdited?.id} listVersion={listBeingEdited.version} open={listMembersDialogOpen} authData={authData} onClose={handleListMembersDialogClose} />}
 
             {!listBeingEdited && <ListSettingsDialog open={listSettingsDialogOpen} data={null} authData={authData} onClose={handleListDialogClose} />}
             {listBeingEdited && <ListSettingsDialog open={listSettingsDialogOpen} data={listBeingEdited} authData={authData} onClose={handleListDialogClose} />}
@@ -1,10 +1,10 @@
-import { Logout, People } from '@mui/icons-material'
+import { Devices, Logout, People } from '@mui/icons-material'
 import { Divider, IconButton, ListItemIcon, Menu, MenuItem, Typography } from '@mui/material'
 import Button from '@mui/material/Button'
 import { useState } from 'react'
 import { useNavigate } from 'react-router-dom'
 import { useAppDispatch, useAppSelector } from '../../app/hooks'
-import { selectAuthenticated, logout } from '../../services/auth/authSplice'
+import { selectAuthenticated, logoutFromServer } from '../../services/auth/authSplice'
 import { resetLists } from '../../services/list/listSplice'
 import { resetTasks } from '../../services/task/taskSplice'
 import { resetUsers, selectUser } from '../../services/user/userSplice'
@@ -32,6 +32,13 @@ export function NavBar() {
     const handleClose = () => {
         setAnchorEl(undefined)
     };
+    const handleLogout = (everywhere: boolean) => {
+        dispatch(logoutFromServer(everywhere))
+        dispatch(resetLists())
+        dispatch(resetTasks())
+        dispatch(resetUsers())
+        navigate("/login")
+    };
 
     return (
         <nav className="navBar">
@@ -91,19 +98,20 @@ export function NavBar() {
                     Friends
                 </MenuItem>
 
-                <MenuItem onClick={() => {
-                    dispatch(logout())
-                    dispatch(resetLists())
-                    dispatch(resetTasks())
-                    dispatch(resetUsers())
-                    navigate("/login")
-                }}>
+                <MenuItem onClick={() => handleLogout(false)}>
                     <ListItemIcon>
                         <Logout fontSize="small" />
                     </ListItemIcon>
                     Logout
                 </MenuItem>
 
+                <MenuItem onClick={() => handleLogout(true)}>
+                    <ListItemIcon>
+                        <Devices fontSize="small" />
+                    </ListItemIcon>
+                    Logout everywhere
+                </MenuItem>
+
                 <Divider />
 
                 <Typography variant='body2' sx={{ fontWeight: "bold", padding: "0.3rem 0.3rem 0.5rem 0.3rem", textAlign: "center" }}>
@@ -115,12 +115,13 @@ export function TaskBoard(props: TaskBoardProps) {
         setEditTaskDialogOpen(false)
     }
 
-    const handleCompletedChange = (taskId: string, initialOrder: number) => (event: React.ChangeEvent<HTMLInputElement>) => {
+    const handleCompletedChange = (taskId: string, version: number, initialOrder: number) => (event: React.ChangeEvent<HTMLInputElement>) => {
         let taskRequest: EditTaskCompletedRequest = {
             authData: authData,
             id: taskId,
             listId: listId,
-            completed: event.target.checked
+            completed: event.target.checked,
+            version: version
         }
 
         dispatch(editTaskCompleted(taskRequest)).then(value => {
@@ -200,7 +201,7 @@ export function TaskBoard(props: TaskBoardProps) {
                                                         </IconButton>
                                                     </Tooltip>
 
-                                                    <Checkbox checked={item.completed} onChange={handleCompletedChange(item.id, item.listOrder)} />
+                                                    <Checkbox checked={item.completed} onChange={handleCompletedChange(item.id, item.version, item.listOrder)} />
                                                 </div>
                                             </Card>
                                         )}
@@ -95,7 +95,8 @@ export function TaskDialog(props: TaskDialogProps) {
             id: data!.id,
             newListId: newListId,
             originalListId: data!.listId,
-            originalListOrder: data!.listOrder
+            originalListOrder: data!.listOrder,
+            version: data!.version
         }
         dispatch(moveTask(moveTaskRequest))
         setMoveTaskMenuOpen(false)
@@ -159,7 +160,8 @@ export function TaskDialog(props: TaskDialogProps) {
                 priority: priorityLevel!,
                 due: (dueDate && dueDateActive) ? moment(dueDate).unix() : -1,
                 plannedStart: (plannedStart && plannedStartActive) ? moment(plannedStart).unix() : -1,
-                plannedEnd: (plannedEnd && plannedEndActive) ? moment(plannedEnd).unix() : -1
+                plannedEnd: (plannedEnd && plannedEndActive) ? moment(plannedEnd).unix() : -1,
+                version: data.version
             }
             dispatch(editTask(editTaskRequest))
         } else {
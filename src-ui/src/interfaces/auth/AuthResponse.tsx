@@ -15,6 +15,8 @@ export interface LoginResponse {
     error: string,
     data: {
         token: string,
-        id: string
+        id: string,
+        expiresAt: number,
+        refreshToken: string
     }
 }
\ No newline at end of file
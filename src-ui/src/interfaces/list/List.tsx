@@ -4,6 +4,7 @@ export interface List {
     owner: string
     private: boolean
     members: Array<string>
+    version: number
 }
 
 export interface ListData {
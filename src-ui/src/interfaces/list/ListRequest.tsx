@@ -12,12 +12,14 @@ export interface EditListRequest {
     id: string
     name: string
     private: boolean
+    version: number
 }
 
 export interface EditListMembersRequest {
     authData: AuthData
     id: string
     members: Array<string>
+    version: number
 }
 
 export interface DeleteListRequest {
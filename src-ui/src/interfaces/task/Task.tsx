@@ -10,6 +10,7 @@ export interface Task {
     plannedStart: number
     plannedEnd: number
     completed: boolean
+    version: number
 }
 
 export interface TaskData {
@@ -22,4 +23,11 @@ export interface TaskData {
     plannedStart: number
     plannedEnd: number
     completed: boolean
+}
+
+export interface TagNode {
+    name: string
+    path: string
+    usage: number
+    children: Array<TagNode>
 }
\ No newline at end of file
@@ -23,13 +23,15 @@ export interface EditTaskRequest {
     due: number,
     plannedStart: number,
     plannedEnd: number,
+    version: number
 }
 
 export interface EditTaskCompletedRequest {
     authData: AuthData
     id: string,
     listId: string,
-    completed: boolean
+    completed: boolean,
+    version: number
 }
 
 export interface MoveTaskRequest {
@@ -37,7 +39,8 @@ export interface MoveTaskRequest {
     id: string,
     originalListId: string,
     newListId: string,
-    originalListOrder: number
+    originalListOrder: number,
+    version: number
 }
 
 export interface DeleteTaskRequest {
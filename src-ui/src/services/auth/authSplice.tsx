@@ -1,6 +1,6 @@
 import { createAsyncThunk, createSlice } from '@reduxjs/toolkit';
 import { RootState } from '../../app/store';
-import { authenticate, createUser } from '../../adapters/auth/auth';
+import { authenticate, createUser, refreshTokens, revokeTokens } from '../../adapters/auth/auth';
 import { Credentials } from '../../interfaces/auth/Credentials';
 import { AxiosError } from 'axios';
 
@@ -8,6 +8,7 @@ export interface AuthState {
     authenticated: boolean
     id: string | null
     token: string | null
+    refreshToken: string | null
     status: 'idle' | 'loading' | 'succeeded' | 'failed',
 }
 
@@ -15,6 +16,7 @@ const initialState: AuthState = {
     authenticated: false,
     id: null,
     token: null,
+    refreshToken: null,
     status: "idle"
 }
 
@@ -45,16 +47,46 @@ export const login = createAsyncThunk("auth/authenticate", async (credentials: C
     }
 })
 
+export const refresh = createAsyncThunk("auth/refresh", async (refreshToken: string, { rejectWithValue }) => {
+    try {
+        const response = await refreshTokens(refreshToken)
+        return response.data
+    } catch (err) {
+        let error = err as AxiosError
+        if (!error.response) {
+            throw err
+        }
+        return rejectWithValue(error.response.data)
+    }
+})
+
+// Logs out on the server as well, so that the refresh token of this device,
+// or of every device, can no longer be used. The local session ends even if
+// the server cannot be reached.
+export const logoutFromServer = createAsyncThunk("auth/logout", async (everywhere: boolean, { getState }) => {
+    const refreshToken = (getState() as RootState).auth.refreshToken
+    if (refreshToken) {
+        try {
+            await revokeTokens(refreshToken, everywhere)
+        } catch (err) {
+            console.error(err)
+        }
+    }
+})
+
+const clearSession = (state: AuthState) => {
+    state.authenticated = false
+    state.id = null
+    state.token = null
+    state.refreshToken = null
+    state.status = "idle"
+}
+
 export const authSlice = createSlice({
     name: 'auth',
     initialState,
     reducers: {
-        logout: (state: AuthState) => {
-            state.authenticated = false
-            state.id = null
-            state.token = null
-            state.status = "idle"
-        }
+        logout: clearSession
     },
     extraReducers: (builder) => {
         builder.addCase(register.fulfilled, (state, action) => {
@@ -63,6 +95,7 @@ export const authSlice = createSlice({
                 state.authenticated = true
                 state.id = action.payload.data.id
                 state.token = action.payload.data.token
+                state.refreshToken = action.payload.data.refreshToken
             }
         })
 
@@ -72,8 +105,25 @@ export const authSlice = createSlice({
                 state.authenticated = true
                 state.id = action.payload.data.id
                 state.token = action.payload.data.token
+                state.refreshToken = action.payload.data.refreshToken
             }
         })
+
+        builder.addCase(refresh.fulfilled, (state, action) => {
+            if (action.payload.success) {
+                state.token = action.payload.data.token
+                state.refreshToken = action.payload.data.refreshToken
+            }
+        })
+
+        builder.addCase(refresh.rejected, (state, action) => {
+            // Only a rejected refresh token ends the session, not a network error.
+            if (action.payload) {
+                clearSession(state)
+            }
+        })
+
+        builder.addCase(logoutFromServer.fulfilled, clearSession)
     }
 })
 
@@ -142,7 +142,8 @@ export const listSlice = createSlice({
                     name: action.payload.data.name,
                     owner: action.payload.data.owner,
                     private: action.payload.data.private,
-                    members: []
+                    members: [],
+                    version: action.payload.data.version
                 })
             }
 
@@ -164,6 +165,7 @@ export const listSlice = createSlice({
                             owner: action.payload.data.owner,
                             private: action.payload.data.private,
                             members: action.payload.data.tags,
+                            version: action.payload.data.version
                         }
 
                         updatedLists.push(updatedList)
@@ -176,6 +178,16 @@ export const listSlice = createSlice({
             state.status = "succeeded"
         })
 
+        builder.addCase(editListMembers.fulfilled, (state, action) => {
+            if (action.payload.success) {
+                for (let list of state.lists) {
+                    if (list.id === action.payload.data.id) {
+                        list.version = action.payload.data.version
+                    }
+                }
+            }
+        })
+
         builder.addCase(deleteList.fulfilled, (state, action) => {
             if (action.payload.success) {
                 let updatedLists: Array<List> = []
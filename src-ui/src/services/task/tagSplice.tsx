@@ -3,6 +3,12 @@ import { RootState } from '../../app/store';
 import { fetchTagsByListId } from '../../adapters/task/task';
 import { AxiosError } from 'axios';
 import { RetrieveTagsByListIdRequest } from '../../interfaces/task/TaskRequest';
+import { TagNode } from '../../interfaces/task/Task';
+
+// Suggestions come back as a tree of nested tags, flatten it for autocomplete.
+function flattenTagTree(nodes: Array<TagNode>): Array<string> {
+    return nodes.flatMap(node => [node.path, ...flattenTagTree(node.children)])
+}
 
 export interface TagState {
     tags: Array<string>
@@ -50,7 +56,7 @@ export const tagSlice = createSlice({
         builder.addCase(retrieveTagsByListId.fulfilled, (state, action) => {
             if (action.payload.success) {
                 if (action.payload.data) {
-                    state.tags = action.payload.data
+                    state.tags = flattenTagTree(action.payload.data)
                 } else {
                     state.tags = []
                 }
@@ -60,7 +60,7 @@ export const editTaskCompleted = createAsyncThunk("task/editCompleted", async (t
 
 export const moveTask = createAsyncThunk("task/moveTask", async (taskRequest: MoveTaskRequest, { rejectWithValue }) => {
     try {
-        const response = await moveTaskToAnotherList(taskRequest.authData, taskRequest.id, taskRequest.originalListId, taskRequest.newListId)
+        const response = await moveTaskToAnotherList(taskRequest.authData, taskRequest.id, taskRequest.originalListId, taskRequest.newListId, taskRequest.version)
         return response.data
     } catch (err) {
         let error = err as AxiosError
@@ -139,7 +139,8 @@ export const taskSlice = createSlice({
                     due: action.payload.data.due,
                     plannedStart: action.payload.data.plannedStart,
                     plannedEnd: action.payload.data.plannedEnd,
-                    completed: action.payload.data.completed
+                    completed: action.payload.data.completed,
+                    version: action.payload.data.version
                 }
                 state.tasks.push(task)
             }
@@ -164,7 +165,8 @@ export const taskSlice = createSlice({
                             due: action.payload.data.due,
                             plannedStart: action.payload.data.plannedStart,
                             plannedEnd: action.payload.data.plannedEnd,
-                            completed: action.payload.data.completed
+                            completed: action.payload.data.completed,
+                            version: action.payload.data.version
                         }
 
                         updatedTasks.push(updatedTask)
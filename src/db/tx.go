@@ -0,0 +1,85 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Executor runs statements either straight on the database or as part of a
+// transaction. Services write through the Executor they are given, so callers
+// can group several service calls into a single unit of work with Transact.
+type Executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Tx is a transaction started by Transact.
+type Tx struct {
+	*sql.Tx
+	afterCommit []func() error
+}
+
+// Transact runs work in a transaction and commits it if work succeeds, or
+// rolls it back otherwise. If ex is already a transaction, work joins it and
+// the outermost Transact decides whether everything is committed.
+func Transact(ex Executor, work func(tx Executor) error) error {
+	if tx, ok := ex.(*Tx); ok {
+		return work(tx)
+	}
+
+	database, ok := ex.(*sql.DB)
+	if !ok {
+		return fmt.Errorf("cannot start a transaction on %T", ex)
+	}
+
+	sqlTx, beginErr := database.Begin()
+	if beginErr != nil {
+		return beginErr
+	}
+	tx := &Tx{Tx: sqlTx}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			sqlTx.Rollback()
+			panic(recovered)
+		}
+	}()
+
+	workErr := work(tx)
+	if workErr != nil {
+		sqlTx.Rollback()
+		return workErr
+	}
+
+	commitErr := sqlTx.Commit()
+	if commitErr != nil {
+		return commitErr
+	}
+
+	// The transaction is already committed, so failures here cannot undo it.
+	for _, action := range tx.afterCommit {
+		actionErr := action()
+		if actionErr != nil {
+			log.Println(actionErr)
+		}
+	}
+
+	return nil
+}
+
+// AfterCommit runs action once the transaction of ex commits, or right away if
+// ex is not a transaction. It is meant for side effects outside the database,
+// such as deleting stored files, that must not happen if the transaction is
+// rolled back.
+func AfterCommit(ex Executor, action func() error) error {
+	tx, ok := ex.(*Tx)
+	if !ok {
+		return action()
+	}
+
+	tx.afterCommit = append(tx.afterCommit, action)
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package db
+
+import "fmt"
+
+// ErrVersionConflict is returned when an edit is based on a version of a row
+// that someone else has changed since.
+var ErrVersionConflict = fmt.Errorf("changed by someone else in the meantime")
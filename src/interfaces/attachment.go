@@ -0,0 +1,34 @@
+package interfaces
+
+type Attachment struct {
+	Id        string `json:"id"`
+	TaskId    string `json:"taskId"`
+	Uploader  string `json:"uploader"`
+	Filename  string `json:"filename"`
+	MimeType  string `json:"mimeType"`
+	Size      int64  `json:"size"` // in bytes
+	CreatedAt int    `json:"createdAt"`
+}
+
+type AttachmentCreationData struct {
+	TaskId   string `json:"taskId"`
+	Uploader string `json:"uploader"`
+	Filename string `json:"filename"`
+	MimeType string `json:"mimeType"`
+	Size     int64  `json:"size"`
+}
+
+type CreateAttachmentResponse struct {
+	BaseResponse
+	Data Attachment `json:"data"`
+}
+
+type DeleteAttachmentResponse struct {
+	BaseResponse
+	Data Attachment `json:"data"`
+}
+
+type RetrieveAttachmentsResponse struct {
+	BaseResponse
+	Data []Attachment `json:"data"`
+}
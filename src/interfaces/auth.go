@@ -1,11 +1,76 @@
 package interfaces
 
-type AuthData struct {
-	Token  string `json:"token"`
-	UserId string `json:"id"`
+type AuthTokens struct {
+	Token        string `json:"token"`
+	UserId       string `json:"id"`
+	ExpiresAt    int    `json:"expiresAt"`    // of the access token
+	RefreshToken string `json:"refreshToken"` // single use, exchanged for new tokens before the access token expires
 }
 
 type AuthResponse struct {
 	BaseResponse
-	Data AuthData `json:"data"`
+	Data AuthTokens `json:"data"`
+}
+
+type Session struct {
+	Id         string `json:"id"`
+	Device     string `json:"device"`
+	UserAgent  string `json:"userAgent"`
+	Ip         string `json:"ip"`
+	CreatedAt  int    `json:"createdAt"`
+	LastSeenAt int    `json:"lastSeenAt"`
+	Current    bool   `json:"current"` // the session the request was made with
+}
+
+// SessionClient describes where a session is used from.
+type SessionClient struct {
+	Device    string // named by the client when logging in
+	UserAgent string
+	Ip        string
+}
+
+type RetrieveSessionsResponse struct {
+	BaseResponse
+	Data []Session `json:"data"`
+}
+
+type RevokeSessionResponse struct {
+	BaseResponse
+	Data Session `json:"data"`
+}
+
+const (
+	SCOPE_TASKS_READ  = "tasks:read"
+	SCOPE_TASKS_WRITE = "tasks:write"
+	SCOPE_LISTS_READ  = "lists:read"
+	SCOPE_LISTS_ADMIN = "lists:admin"
+)
+
+type PersonalAccessToken struct {
+	Id         string   `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  int      `json:"createdAt"`
+	ExpiresAt  int      `json:"expiresAt"`  // -1 if it never expires
+	LastUsedAt int      `json:"lastUsedAt"` // -1 if never used
+}
+
+type CreatedPersonalAccessToken struct {
+	PersonalAccessToken
+	Token string `json:"token"` // only ever shown on creation
+}
+
+type CreatePersonalAccessTokenResponse struct {
+	BaseResponse
+	Data CreatedPersonalAccessToken `json:"data"`
+}
+
+type RetrievePersonalAccessTokensResponse struct {
+	BaseResponse
+	Data []PersonalAccessToken `json:"data"`
+}
+
+type DeletePersonalAccessTokenResponse struct {
+	BaseResponse
+	Data PersonalAccessToken `json:"data"`
 }
@@ -1,5 +1,8 @@
 package interfaces
 
+// ANY_VERSION skips the version check of an edit.
+const ANY_VERSION = -1
+
 type BaseResponse struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error"`
@@ -0,0 +1,41 @@
+package interfaces
+
+type Comment struct {
+	Id        string `json:"id"`
+	TaskId    string `json:"taskId"`
+	Author    string `json:"author"`
+	Body      string `json:"body"` // markdown
+	CreatedAt int    `json:"createdAt"`
+	UpdatedAt int    `json:"updatedAt"`
+}
+
+type CommentCreationData struct {
+	TaskId string `json:"taskId"`
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
+type CommentEditionData struct {
+	Id   string `json:"id"`
+	Body string `json:"body"`
+}
+
+type CreateCommentResponse struct {
+	BaseResponse
+	Data Comment `json:"data"`
+}
+
+type EditCommentResponse struct {
+	BaseResponse
+	Data Comment `json:"data"`
+}
+
+type DeleteCommentResponse struct {
+	BaseResponse
+	Data Comment `json:"data"`
+}
+
+type RetrieveCommentsResponse struct {
+	BaseResponse
+	Data []Comment `json:"data"`
+}
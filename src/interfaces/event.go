@@ -0,0 +1,26 @@
+package interfaces
+
+const (
+	CHANGE_TASK_CREATED    = "task.created"
+	CHANGE_TASK_UPDATED    = "task.updated"
+	CHANGE_TASK_MOVED      = "task.moved"
+	CHANGE_TASK_DELETED    = "task.deleted"
+	CHANGE_TASKS_REORDERED = "tasks.reordered"
+	CHANGE_LIST_UPDATED    = "list.updated"
+	CHANGE_LIST_MEMBERS    = "list.members"
+	CHANGE_LIST_DELETED    = "list.deleted"
+
+	// Sent instead of a list change to a subscriber who can no longer see
+	// the list, after which no more events of that list are delivered.
+	CHANGE_LIST_ACCESS_REVOKED = "list.accessRevoked"
+)
+
+type ChangeEvent struct {
+	Type      string `json:"type"`
+	ListId    string `json:"listId"`
+	Actor     string `json:"actor"`
+	Timestamp int    `json:"timestamp"`
+	Task      *Task  `json:"task"`  // nil unless a single task changed
+	Tasks     []Task `json:"tasks"` // the whole list order, set on reorders only
+	List      *List  `json:"list"`  // nil unless the list itself changed
+}
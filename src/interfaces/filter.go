@@ -0,0 +1,28 @@
+package interfaces
+
+type Filter struct {
+	Id    string `json:"id"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	Query string `json:"query"` // e.g. tag:work priority>=2 due<7d !completed
+}
+
+type CreateFilterResponse struct {
+	BaseResponse
+	Data Filter `json:"data"`
+}
+
+type EditFilterResponse struct {
+	BaseResponse
+	Data Filter `json:"data"`
+}
+
+type DeleteFilterResponse struct {
+	BaseResponse
+	Data Filter `json:"data"`
+}
+
+type RetrieveFiltersResponse struct {
+	BaseResponse
+	Data []Filter `json:"data"`
+}
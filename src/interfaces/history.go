@@ -0,0 +1,36 @@
+package interfaces
+
+const (
+	TASK_EVENT_CREATE   = "create"
+	TASK_EVENT_EDIT     = "edit"
+	TASK_EVENT_COMPLETE = "complete"
+	TASK_EVENT_MOVE     = "move"
+	TASK_EVENT_REORDER  = "reorder"
+	TASK_EVENT_DELETE   = "delete"
+	TASK_EVENT_RESTORE  = "restore"
+	TASK_EVENT_ARCHIVE  = "archive"
+	TASK_EVENT_SUBTASK  = "subtask"
+
+	// Actor recorded for changes made by background jobs rather than a user.
+	TASK_EVENT_SYSTEM_ACTOR = "system"
+)
+
+type TaskFieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type TaskEvent struct {
+	Id        string                     `json:"id"`
+	TaskId    string                     `json:"taskId"`
+	ListId    string                     `json:"listId"`
+	Actor     string                     `json:"actor"`
+	Type      string                     `json:"type"`
+	Timestamp int                        `json:"timestamp"`
+	Changes   map[string]TaskFieldChange `json:"changes"` // keyed by task json field, or "subtask" on subtask events
+}
+
+type RetrieveTaskHistoryResponse struct {
+	BaseResponse
+	Data []TaskEvent `json:"data"`
+}
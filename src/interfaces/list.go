@@ -1,11 +1,16 @@
 package interfaces
 
 type List struct {
-	Id      string   `json:"id"`
-	Name    string   `json:"name"`
-	Owner   string   `json:"owner"`
-	Private bool     `json:"private"`
-	Members []string `json:"members"`
+	Id              string   `json:"id"`
+	Name            string   `json:"name"`
+	Owner           string   `json:"owner"`
+	Private         bool     `json:"private"`
+	Members         []string `json:"members"`
+	DeletedAt       int      `json:"deletedAt"`       // -1 unless in the trash
+	DeletedBy       string   `json:"deletedBy"`       // "" unless in the trash
+	ArchivedAt      int      `json:"archivedAt"`      // -1 unless archived
+	AutoArchiveDays int      `json:"autoArchiveDays"` // -1 if completed tasks are never auto-archived
+	Version         int      `json:"version"`         // bumped on every change, sent as the ETag
 }
 
 type CreateListResponse struct {
@@ -23,11 +28,23 @@ type DeleteListResponse struct {
 	Data List `json:"data"`
 }
 
+type ArchiveListResponse struct {
+	BaseResponse
+	Data List `json:"data"`
+}
+
+type RestoreListResponse struct {
+	BaseResponse
+	Data List `json:"data"`
+}
+
 type BasicListData struct {
-	Id      string `json:"id"`
-	Name    string `json:"name"`
-	Owner   string `json:"owner"`
-	Private bool   `json:"private"`
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	Owner      string `json:"owner"`
+	Private    bool   `json:"private"`
+	ArchivedAt int    `json:"archivedAt"`
+	Version    int    `json:"version"`
 }
 
 type RetrieveListResponse struct {
@@ -0,0 +1,22 @@
+package interfaces
+
+const (
+	SEARCH_RESULT_TASK    = "task"
+	SEARCH_RESULT_COMMENT = "comment"
+	SEARCH_RESULT_LIST    = "list"
+)
+
+type SearchResult struct {
+	Type    string  `json:"type"` // one of the SEARCH_RESULT_* constants
+	Id      string  `json:"id"`
+	ListId  string  `json:"listId"`
+	TaskId  string  `json:"taskId"`  // "" for lists
+	Title   string  `json:"title"`   // escaped HTML with matches wrapped in <mark></mark>
+	Snippet string  `json:"snippet"` // escaped HTML with matches wrapped in <mark></mark>
+	Rank    float64 `json:"rank"`
+}
+
+type SearchResponse struct {
+	BaseResponse
+	Data []SearchResult `json:"data"`
+}
@@ -0,0 +1,57 @@
+package interfaces
+
+type Subtask struct {
+	Id        string `json:"id"`
+	TaskId    string `json:"taskId"`
+	ParentId  string `json:"parentId"` // "" unless nested under another subtask
+	Title     string `json:"title"`
+	ListOrder int    `json:"listOrder"` // among the subtasks sharing its parent
+	Completed bool   `json:"completed"`
+}
+
+type CreateSubtaskResponse struct {
+	BaseResponse
+	Data Subtask `json:"data"`
+}
+
+type EditSubtaskResponse struct {
+	BaseResponse
+	Data Subtask `json:"data"`
+}
+
+type DeleteSubtaskResponse struct {
+	BaseResponse
+	Data Subtask `json:"data"`
+}
+
+type SubtaskCreationData struct {
+	TaskId   string `json:"taskId"`
+	ParentId string `json:"parentId"`
+	Title    string `json:"title"`
+}
+
+type SubtaskEditionData struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type SubtaskEditCompletedData struct {
+	Id        string `json:"id"`
+	Completed bool   `json:"completed"`
+}
+
+type SubtaskCompletedData struct {
+	Subtask        Subtask `json:"subtask"`
+	Task           Task    `json:"task"`
+	NextOccurrence *Task   `json:"nextOccurrence"` // nil unless a recurring parent was completed
+}
+
+type EditSubtaskCompletedResponse struct {
+	BaseResponse
+	Data SubtaskCompletedData `json:"data"`
+}
+
+type RetrieveSubtasksResponse struct {
+	BaseResponse
+	Data []Subtask `json:"data"`
+}
@@ -0,0 +1,52 @@
+package interfaces
+
+type Tag struct {
+	ListId      string `json:"listId"`
+	Name        string `json:"name"`
+	Color       string `json:"color"` // hex colour such as #ff8800, empty if unset
+	Description string `json:"description"`
+	Usage       int    `json:"usage"`       // number of live tasks using the tag or any tag nested under it
+	DirectUsage int    `json:"directUsage"` // number of live tasks using exactly this tag
+}
+
+type TagNode struct {
+	Name        string    `json:"name"` // last level of the path, e.g. billing
+	Path        string    `json:"path"` // full tag, e.g. work/clientA/billing
+	Color       string    `json:"color"`
+	Description string    `json:"description"`
+	Usage       int       `json:"usage"`
+	DirectUsage int       `json:"directUsage"`
+	Children    []TagNode `json:"children"`
+}
+
+type TagEditionData struct {
+	ListId      string `json:"listId"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type RetrieveListTagsResponse struct {
+	BaseResponse
+	Data []Tag `json:"data"`
+}
+
+type EditTagResponse struct {
+	BaseResponse
+	Data Tag `json:"data"`
+}
+
+type RenameTagResponse struct {
+	BaseResponse
+	Data []Task `json:"data"`
+}
+
+type MergeTagsResponse struct {
+	BaseResponse
+	Data []Task `json:"data"`
+}
+
+type DeleteTagResponse struct {
+	BaseResponse
+	Data []Task `json:"data"`
+}
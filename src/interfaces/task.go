@@ -4,14 +4,28 @@ type Task struct {
 	Id           string   `json:"id"`
 	Owner        string   `json:"owner"`
 	Title        string   `json:"title"`
+	Description  string   `json:"description"` // markdown
 	Tags         []string `json:"tags"`
 	ListId       string   `json:"listId"`
-	ListOrder    int      `json:"listOrder"`    // -1 for serial auto increment
+	ListOrder    int      `json:"listOrder"`    // position among the shown tasks of the list, -1 in the trash or the archive
 	Priority     int      `json:"priority"`     // -1 if unset
 	Due          int      `json:"due"`          // -1 if nil
 	PlannedStart int      `json:"plannedStart"` // -1 if nil
 	PlannedEnd   int      `json:"plannedEnd"`   // -1 if nil
 	Completed    bool     `json:"completed"`
+	Recurrence   string   `json:"recurrence"` // RFC 5545 RRULE, "" if not recurring
+	Assignees    []string `json:"assignees"`
+	DeletedAt    int      `json:"deletedAt"`  // -1 unless in the trash
+	DeletedBy    string   `json:"deletedBy"`  // "" unless in the trash
+	ArchivedAt   int      `json:"archivedAt"` // -1 unless archived
+	CreatedAt    int      `json:"createdAt"`
+	UpdatedAt    int      `json:"updatedAt"`
+	CompletedAt  int      `json:"completedAt"` // -1 unless completed
+	BlockedBy    []string `json:"blockedBy"`   // only populated when retrieving tasks by list
+	Blocking     []string `json:"blocking"`    // only populated when retrieving tasks by list
+	Blocked      bool     `json:"blocked"`     // true if any task in BlockedBy is still open
+	Rank         string   `json:"rank"`        // sort key of the task within its list
+	Version      int      `json:"version"`     // bumped on every change except reordering, sent as the ETag
 }
 
 type CreateTaskResponse struct {
@@ -24,6 +38,12 @@ type EditTaskResponse struct {
 	Data Task `json:"data"`
 }
 
+type EditTaskCompletedResponse struct {
+	BaseResponse
+	Data           Task  `json:"data"`
+	NextOccurrence *Task `json:"nextOccurrence"` // nil unless a recurring task was completed
+}
+
 type MoveTaskResponse struct {
 	BaseResponse
 	Data Task `json:"data"`
@@ -34,36 +54,71 @@ type DeleteTaskResponse struct {
 	Data Task `json:"data"`
 }
 
+type ArchiveTaskResponse struct {
+	BaseResponse
+	Data Task `json:"data"`
+}
+
+type RestoreTaskResponse struct {
+	BaseResponse
+	Data Task `json:"data"`
+}
+
 type TaskCreationData struct {
 	Owner        string   `json:"owner"`
 	Title        string   `json:"title"`
+	Description  string   `json:"description"` // markdown
 	Tags         []string `json:"tags"`
 	ListId       string   `json:"listId"`
 	Priority     int      `json:"priority"`
 	Due          int      `json:"due"`          // -1 if nil
 	PlannedStart int      `json:"plannedStart"` // -1 if nil
 	PlannedEnd   int      `json:"plannedEnd"`   // -1 if nil
+	Recurrence   string   `json:"recurrence"`   // "" if not recurring
+	Assignees    []string `json:"assignees"`
 }
 
 type TaskEditionData struct {
 	Id           string   `json:"id"`
 	Title        string   `json:"title"`
+	Description  string   `json:"description"` // markdown
 	Tags         []string `json:"tags"`
 	Priority     int      `json:"priority"`
 	Due          int      `json:"due"`          // -1 if nil
 	PlannedStart int      `json:"plannedStart"` // -1 if nil
 	PlannedEnd   int      `json:"plannedEnd"`   // -1 if nil
+	Recurrence   string   `json:"recurrence"`   // "" if not recurring
+	Assignees    []string `json:"assignees"`
+	Version      int      `json:"version"` // version the edit is based on, or ANY_VERSION
 }
 
 type TaskEditCompletedData struct {
 	Id        string `json:"id"`
 	Completed bool   `json:"completed"`
+	Force     bool   `json:"force"`   // complete the task even if it is blocked by open tasks
+	Version   int    `json:"version"` // version the edit is based on, or ANY_VERSION
+}
+
+type TaskEditAssigneesData struct {
+	Id        string   `json:"id"`
+	Assignees []string `json:"assignees"`
+	Version   int      `json:"version"` // version the edit is based on, or ANY_VERSION
+}
+
+type TaskDependency struct {
+	TaskId    string `json:"taskId"`
+	BlockedBy string `json:"blockedBy"`
+}
+
+type TaskDependencyResponse struct {
+	BaseResponse
+	Data TaskDependency `json:"data"`
 }
 
 type MoveTaskData struct {
-	Id           string `json:"id"`
-	NewListId    string `json:"newListId"`
-	NewListOrder int    `json:"newListOrder"`
+	Id        string `json:"id"`
+	NewListId string `json:"newListId"`
+	Version   int    `json:"version"` // version the move is based on, or ANY_VERSION
 }
 
 type TaskReorderData struct {
@@ -73,11 +128,6 @@ type TaskReorderData struct {
 	NewOrder     int    `json:"newOrder"`
 }
 
-type BasicTaskReorderData struct {
-	Id        string `json:"id"`
-	ListOrder int    `json:"listOrder"`
-}
-
 type RetrieveTasksResponse struct {
 	BaseResponse
 	Data []Task `json:"data"`
@@ -85,5 +135,5 @@ type RetrieveTasksResponse struct {
 
 type RetrieveTagsResponse struct {
 	BaseResponse
-	Data []string `json:"data"`
+	Data []TagNode `json:"data"`
 }
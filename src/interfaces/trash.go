@@ -0,0 +1,12 @@
+package interfaces
+
+type Trash struct {
+	Tasks         []Task `json:"tasks"` // tasks deleted on their own; tasks of a deleted list come back with it
+	Lists         []List `json:"lists"`
+	RetentionDays int    `json:"retentionDays"`
+}
+
+type RetrieveTrashResponse struct {
+	BaseResponse
+	Data Trash `json:"data"`
+}
@@ -6,6 +6,10 @@ import (
 
 	"github.com/beebeeoii/do-gether/db"
 	router "github.com/beebeeoii/do-gether/routers"
+	archiveService "github.com/beebeeoii/do-gether/services/archive"
+	rebalanceService "github.com/beebeeoii/do-gether/services/rebalance"
+	trashService "github.com/beebeeoii/do-gether/services/trash"
+	"github.com/beebeeoii/do-gether/storage"
 	"github.com/joho/godotenv"
 )
 
@@ -20,5 +24,18 @@ func main() {
 		log.Fatalln(psqlDbErr)
 	}
 
+	storageErr := storage.Init()
+	if storageErr != nil {
+		log.Fatalln(storageErr)
+	}
+
+	trashErr := trashService.Init()
+	if trashErr != nil {
+		log.Fatalln(trashErr)
+	}
+
+	archiveService.Init()
+	rebalanceService.Init()
+
 	router.Init(os.Getenv("SERVER_ADD"))
 }
@@ -0,0 +1,360 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	attachmentService "github.com/beebeeoii/do-gether/services/attachment"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+type createAttachmentForm struct {
+	TaskId string `form:"taskId" validate:"required,min=1,max=20"`
+}
+
+type attachmentParams struct {
+	Id string `form:"attachmentId" validate:"required,min=1,max=20"`
+}
+
+type retrieveAttachmentsByTaskIdParams struct {
+	TaskId string `form:"taskId" validate:"required,min=1,max=20"`
+}
+
+const (
+	ATTACHMENT_FORM_KEY    = "file"
+	MAX_ATTACHMENT_SIZE    = 10 << 20
+	MAX_MULTIPART_OVERHEAD = 1 << 20
+	MAX_FILENAME_LENGTH    = 255
+	SNIFF_LENGTH           = 512
+
+	REQUEST_TOO_LARGE_ERROR = "http: request body too large"
+)
+
+func verifyUserTaskReadPerms(taskId string, userId string) (interfaces.List, error) {
+	listId, retrieveListIdErr := taskService.RetrieveListIdByTaskId(taskId)
+	if retrieveListIdErr != nil {
+		return interfaces.List{}, retrieveListIdErr
+	}
+
+	list, retrieveListErr := listService.RetrieveListById(listId)
+	if retrieveListErr != nil {
+		return list, retrieveListErr
+	}
+
+	if !validator.HasListReadWritePermission(list, userId) {
+		return list, fmt.Errorf("access denied")
+	}
+
+	return list, nil
+}
+
+// sniffMimeType detects the content type from the file itself rather than
+// trusting the one declared by the client, then rewinds the file.
+func sniffMimeType(file io.ReadSeeker) (string, error) {
+	buffer := make([]byte, SNIFF_LENGTH)
+
+	n, readErr := io.ReadFull(file, buffer)
+	if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+		return "", readErr
+	}
+
+	_, seekErr := file.Seek(0, io.SeekStart)
+	if seekErr != nil {
+		return "", seekErr
+	}
+
+	return http.DetectContentType(buffer[:n]), nil
+}
+
+func CreateAttachment(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MAX_ATTACHMENT_SIZE+MAX_MULTIPART_OVERHEAD)
+
+	var reqForm createAttachmentForm
+
+	reqFormErr := c.ShouldBind(&reqForm)
+	if reqFormErr != nil {
+		if reqFormErr.Error() == REQUEST_TOO_LARGE_ERROR {
+			c.JSON(http.StatusRequestEntityTooLarge, interfaces.BaseResponse{
+				Success: false,
+				Error:   fmt.Errorf("attachment exceeds %d bytes", MAX_ATTACHMENT_SIZE).Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqFormErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqForm)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	_, verifyErr := verifyUserTaskReadPerms(reqForm.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	fileHeader, formFileErr := c.FormFile(ATTACHMENT_FORM_KEY)
+	if formFileErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   formFileErr.Error(),
+		})
+		return
+	}
+
+	if fileHeader.Size > MAX_ATTACHMENT_SIZE {
+		c.JSON(http.StatusRequestEntityTooLarge, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("attachment exceeds %d bytes", MAX_ATTACHMENT_SIZE).Error(),
+		})
+		return
+	}
+
+	filename := filepath.Base(fileHeader.Filename)
+	if filename == "." || filename == string(filepath.Separator) || len(filename) > MAX_FILENAME_LENGTH {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("invalid filename").Error(),
+		})
+		return
+	}
+
+	file, openErr := fileHeader.Open()
+	if openErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   openErr.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	mimeType, sniffErr := sniffMimeType(file)
+	if sniffErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   sniffErr.Error(),
+		})
+		return
+	}
+
+	newAttachment, createAttachmentErr := attachmentService.CreateAttachment(db.Database, interfaces.AttachmentCreationData{
+		TaskId:   reqForm.TaskId,
+		Uploader: userId,
+		Filename: filename,
+		MimeType: mimeType,
+		Size:     fileHeader.Size,
+	}, file)
+	if createAttachmentErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   createAttachmentErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.CreateAttachmentResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: newAttachment,
+	})
+}
+
+func DownloadAttachment(c *gin.Context) {
+	var reqParams attachmentParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	attachment, retrieveAttachmentErr := attachmentService.RetrieveAttachmentById(reqParams.Id)
+	if retrieveAttachmentErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveAttachmentErr.Error(),
+		})
+		return
+	}
+
+	_, verifyErr := verifyUserTaskReadPerms(attachment.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	content, retrieveContentErr := attachmentService.RetrieveAttachmentContent(attachment.Id)
+	if retrieveContentErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveContentErr.Error(),
+		})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.MimeType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func DeleteAttachment(c *gin.Context) {
+	var reqParams attachmentParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	attachment, retrieveAttachmentErr := attachmentService.RetrieveAttachmentById(reqParams.Id)
+	if retrieveAttachmentErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveAttachmentErr.Error(),
+		})
+		return
+	}
+
+	list, verifyErr := verifyUserTaskReadPerms(attachment.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	if attachment.Uploader != userId && !validator.HasListEditPermission(list, userId) {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("access denied").Error(),
+		})
+		return
+	}
+
+	deletedAttachment, deleteAttachmentErr := attachmentService.DeleteAttachment(db.Database, reqParams.Id)
+	if deleteAttachmentErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   deleteAttachmentErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.DeleteAttachmentResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: deletedAttachment,
+	})
+}
+
+func RetrieveAttachmentsByTaskId(c *gin.Context) {
+	var reqParams retrieveAttachmentsByTaskIdParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	_, verifyErr := verifyUserTaskReadPerms(reqParams.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	attachments, retrieveAttachmentsErr := attachmentService.RetrieveAttachmentsByTaskId(reqParams.TaskId)
+	if retrieveAttachmentsErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveAttachmentsErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveAttachmentsResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: attachments,
+	})
+}
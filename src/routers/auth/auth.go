@@ -1,8 +1,11 @@
 package router
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 
+	"github.com/beebeeoii/do-gether/db"
 	"github.com/beebeeoii/do-gether/interfaces"
 	validator "github.com/beebeeoii/do-gether/routers/validator"
 	authService "github.com/beebeeoii/do-gether/services/auth"
@@ -10,20 +13,66 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+type logInBody struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Device   string `json:"device" validate:"max=60"` // shown in the list of sessions
+}
+
 type authenticateParams struct {
 	Username string `form:"username" validate:"required"`
 	Password string `form:"password" validate:"required"`
+	Device   string `form:"device" validate:"max=60"` // shown in the list of sessions
+}
+
+type refreshTokensBody struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type logOutBody struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+	Everywhere   bool   `json:"everywhere"` // log out every device of the user
 }
 
 const (
-	INVALID_USERNAME_ERROR    = "sql: no rows in result set"
-	INVALID_USERNAME_RESPONSE = "Incorrect username/password"
-	INVALID_PASSWORD_RESPONSE = "Incorrect username/password"
+	INVALID_CREDENTIALS_RESPONSE = "Incorrect username/password"
+
+	LOG_IN_ROUTE = "/auth/login"
 )
 
+func LogIn(c *gin.Context) {
+	var requestBody logInBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	logIn(c, requestBody.Username, requestBody.Password, requestBody.Device)
+}
+
+// AuthenticateUser logs in with the credentials in the query string, where
+// they end up in access logs, proxies and browser history. It is deprecated
+// in favour of LogIn and only routed when LEGACY_LOGIN_ENABLED is set.
 func AuthenticateUser(c *gin.Context) {
 	var reqParams authenticateParams
 
+	c.Header("Deprecation", "true")
+	c.Header("Link", "<"+LOG_IN_ROUTE+">; rel=\"successor-version\"")
+
 	reqParamsErr := c.BindQuery(&reqParams)
 	if reqParamsErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
@@ -42,55 +91,157 @@ func AuthenticateUser(c *gin.Context) {
 		return
 	}
 
-	hashedPassword, retrieveErr := userService.RetrieveUserHashedPassword(reqParams.Username)
+	logIn(c, reqParams.Username, reqParams.Password, reqParams.Device)
+}
+
+func logIn(c *gin.Context, username string, password string, device string) {
+	hashedPassword, retrieveErr := userService.RetrieveUserHashedPassword(username)
 	if retrieveErr != nil {
-		if retrieveErr.Error() == INVALID_USERNAME_ERROR {
+		if errors.Is(retrieveErr, sql.ErrNoRows) {
 			c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
 				Success: false,
-				Error:   INVALID_USERNAME_RESPONSE,
-			})
-		} else {
-			c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
-				Success: false,
-				Error:   retrieveErr.Error(),
+				Error:   INVALID_CREDENTIALS_RESPONSE,
 			})
+			return
 		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveErr.Error(),
+		})
 		return
 	}
 
-	if authService.DoesPasswordMatchHash(reqParams.Password, hashedPassword) {
-		userId, userIdErr := userService.RetrieveUserIdByUsername(reqParams.Username)
-		if userIdErr != nil {
-			c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+	if !authService.DoesPasswordMatchHash(password, hashedPassword) {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   INVALID_CREDENTIALS_RESPONSE,
+		})
+		return
+	}
+
+	userId, userIdErr := userService.RetrieveUserIdByUsername(username)
+	if userIdErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   userIdErr.Error(),
+		})
+		return
+	}
+
+	authTokens, issueTokensErr := authService.IssueTokens(db.Database, userId, interfaces.SessionClient{
+		Device:    device,
+		UserAgent: c.Request.UserAgent(),
+		Ip:        c.ClientIP(),
+	})
+	if issueTokensErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   issueTokensErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.AuthResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: authTokens,
+	})
+}
+
+func RefreshTokens(c *gin.Context) {
+	var requestBody refreshTokensBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	authTokens, refreshTokensErr := authService.RefreshTokens(db.Database, requestBody.RefreshToken, interfaces.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		Ip:        c.ClientIP(),
+	})
+	if refreshTokensErr != nil {
+		if errors.Is(refreshTokensErr, authService.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
 				Success: false,
-				Error:   userIdErr.Error(),
+				Error:   refreshTokensErr.Error(),
 			})
 			return
 		}
 
-		jwtToken, jwtTokenErr := authService.GenerateJwt(userId)
-		if jwtTokenErr != nil {
-			c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   refreshTokensErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.AuthResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: authTokens,
+	})
+}
+
+// LogOut ends the session of a device, or of every device if asked to.
+func LogOut(c *gin.Context) {
+	var requestBody logOutBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	revokeErr := authService.RevokeRefreshToken(db.Database, requestBody.RefreshToken, requestBody.Everywhere)
+	if revokeErr != nil {
+		if errors.Is(revokeErr, authService.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
 				Success: false,
-				Error:   jwtTokenErr.Error(),
+				Error:   revokeErr.Error(),
 			})
 			return
 		}
 
-		c.JSON(http.StatusOK, interfaces.AuthResponse{
-			BaseResponse: interfaces.BaseResponse{
-				Success: true,
-				Error:   "",
-			},
-			Data: interfaces.AuthData{
-				Token:  jwtToken,
-				UserId: userId,
-			},
-		})
-	} else {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
-			Error:   INVALID_PASSWORD_RESPONSE,
+			Error:   revokeErr.Error(),
 		})
+		return
 	}
+
+	c.JSON(http.StatusOK, interfaces.BaseResponse{
+		Success: true,
+		Error:   "",
+	})
 }
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	authService "github.com/beebeeoii/do-gether/services/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type revokeSessionParams struct {
+	Id string `form:"sessionId" validate:"required,min=1,max=20"`
+}
+
+func RetrieveSessions(c *gin.Context) {
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+	currentSessionId := c.GetString(validator.SESSION_ID_CONTEXT_KEY)
+
+	sessions, retrieveSessionsErr := authService.RetrieveSessionsByUserId(userId)
+	if retrieveSessionsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveSessionsErr.Error(),
+		})
+		return
+	}
+
+	for index := range sessions {
+		sessions[index].Current = sessions[index].Id == currentSessionId
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveSessionsResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: sessions,
+	})
+}
+
+func RevokeSession(c *gin.Context) {
+	var reqParams revokeSessionParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	revokedSession, revokeSessionErr := authService.RevokeSession(db.Database, userId, reqParams.Id)
+	if revokeSessionErr != nil {
+		if errors.Is(revokeSessionErr, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+				Success: false,
+				Error:   revokeSessionErr.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   revokeSessionErr.Error(),
+		})
+		return
+	}
+
+	revokedSession.Current = revokedSession.Id == c.GetString(validator.SESSION_ID_CONTEXT_KEY)
+
+	c.JSON(http.StatusOK, interfaces.RevokeSessionResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: revokedSession,
+	})
+}
@@ -0,0 +1,144 @@
+package router
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	authService "github.com/beebeeoii/do-gether/services/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type createPersonalAccessTokenBody struct {
+	Name      string   `json:"name" validate:"required,min=1,max=60"`
+	Scopes    []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=tasks:read tasks:write lists:read lists:admin"`
+	ExpiresAt int      `json:"expiresAt" validate:"required,min=-1"` // -1 if it should never expire
+}
+
+type deletePersonalAccessTokenParams struct {
+	Id string `form:"tokenId" validate:"required,min=1,max=20"`
+}
+
+func CreatePersonalAccessToken(c *gin.Context) {
+	var requestBody createPersonalAccessTokenBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	if requestBody.ExpiresAt != -1 && int64(requestBody.ExpiresAt) <= time.Now().Unix() {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("expiry must be in the future").Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	newToken, createTokenErr := authService.CreatePersonalAccessToken(db.Database, userId, requestBody.Name, requestBody.Scopes, requestBody.ExpiresAt)
+	if createTokenErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   createTokenErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.CreatePersonalAccessTokenResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: newToken,
+	})
+}
+
+func RetrievePersonalAccessTokens(c *gin.Context) {
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	tokens, retrieveTokensErr := authService.RetrievePersonalAccessTokensByUserId(userId)
+	if retrieveTokensErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveTokensErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrievePersonalAccessTokensResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: tokens,
+	})
+}
+
+func DeletePersonalAccessToken(c *gin.Context) {
+	var reqParams deletePersonalAccessTokenParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	deletedToken, deleteTokenErr := authService.DeletePersonalAccessToken(db.Database, userId, reqParams.Id)
+	if deleteTokenErr != nil {
+		if errors.Is(deleteTokenErr, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+				Success: false,
+				Error:   deleteTokenErr.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   deleteTokenErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.DeletePersonalAccessTokenResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: deletedToken,
+	})
+}
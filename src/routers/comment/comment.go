@@ -0,0 +1,291 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	commentService "github.com/beebeeoii/do-gether/services/comment"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+type createCommentBody struct {
+	TaskId string `json:"taskId" validate:"min=1,max=20,required"`
+	Body   string `json:"body" validate:"min=1,max=10000,required"`
+}
+
+type editCommentBody struct {
+	Id   string `json:"id" validate:"min=1,max=20,required"`
+	Body string `json:"body" validate:"min=1,max=10000,required"`
+}
+
+type deleteCommentParams struct {
+	Id string `form:"commentId" validate:"required,min=1,max=20"`
+}
+
+type retrieveCommentsByTaskIdParams struct {
+	TaskId string `form:"taskId" validate:"required,min=1,max=20"`
+}
+
+func verifyUserTaskWritePerms(taskId string, userId string) (interfaces.List, error) {
+	listId, retrieveListIdErr := taskService.RetrieveListIdByTaskId(taskId)
+	if retrieveListIdErr != nil {
+		return interfaces.List{}, retrieveListIdErr
+	}
+
+	list, retrieveListErr := listService.RetrieveListById(listId)
+	if retrieveListErr != nil {
+		return list, retrieveListErr
+	}
+
+	if !validator.HasListReadWritePermission(list, userId) {
+		return list, fmt.Errorf("access denied")
+	}
+
+	return list, nil
+}
+
+func CreateComment(c *gin.Context) {
+	var requestBody createCommentBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	_, verifyErr := verifyUserTaskWritePerms(requestBody.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	newComment, createCommentErr := commentService.CreateComment(db.Database, interfaces.CommentCreationData{
+		TaskId: requestBody.TaskId,
+		Author: userId,
+		Body:   requestBody.Body,
+	})
+	if createCommentErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   createCommentErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.CreateCommentResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: newComment,
+	})
+}
+
+func EditComment(c *gin.Context) {
+	var requestBody editCommentBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	comment, retrieveCommentErr := commentService.RetrieveCommentById(requestBody.Id)
+	if retrieveCommentErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveCommentErr.Error(),
+		})
+		return
+	}
+
+	_, verifyErr := verifyUserTaskWritePerms(comment.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	if comment.Author != userId {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("access denied").Error(),
+		})
+		return
+	}
+
+	updatedComment, editCommentErr := commentService.EditComment(db.Database, interfaces.CommentEditionData{
+		Id:   requestBody.Id,
+		Body: requestBody.Body,
+	})
+	if editCommentErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   editCommentErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.EditCommentResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedComment,
+	})
+}
+
+func DeleteComment(c *gin.Context) {
+	var reqParams deleteCommentParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	comment, retrieveCommentErr := commentService.RetrieveCommentById(reqParams.Id)
+	if retrieveCommentErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveCommentErr.Error(),
+		})
+		return
+	}
+
+	list, verifyErr := verifyUserTaskWritePerms(comment.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	if comment.Author != userId && !validator.HasListEditPermission(list, userId) {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("access denied").Error(),
+		})
+		return
+	}
+
+	deletedComment, deleteCommentErr := commentService.DeleteComment(db.Database, reqParams.Id)
+	if deleteCommentErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   deleteCommentErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.DeleteCommentResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: deletedComment,
+	})
+}
+
+func RetrieveCommentsByTaskId(c *gin.Context) {
+	var reqParams retrieveCommentsByTaskIdParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	_, verifyErr := verifyUserTaskWritePerms(reqParams.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	comments, retrieveCommentsErr := commentService.RetrieveCommentsByTaskId(reqParams.TaskId)
+	if retrieveCommentsErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveCommentsErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveCommentsResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: comments,
+	})
+}
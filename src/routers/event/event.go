@@ -0,0 +1,129 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	eventService "github.com/beebeeoii/do-gether/services/event"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	"github.com/gin-gonic/gin"
+)
+
+type subscribeToChangesParams struct {
+	ListIds []string `form:"listId" validate:"max=50,unique,dive,min=1,max=20"`
+}
+
+const (
+	// Keeps idle streams from being closed by proxies along the way.
+	HEARTBEAT_INTERVAL = 30 * time.Second
+)
+
+func verifyUserReadPerms(listId string, userId string) error {
+	list, retrieveListErr := listService.RetrieveListById(listId)
+	if retrieveListErr != nil {
+		return retrieveListErr
+	}
+
+	if !validator.HasListReadWritePermission(list, userId) {
+		return fmt.Errorf("access denied")
+	}
+
+	return nil
+}
+
+// SubscribeToChanges streams the changes of the given lists as server-sent
+// events, named after the change type and carrying an interfaces.ChangeEvent.
+// Membership changes of lists the user is added to or removed from are
+// streamed too. Browsers streaming with EventSource, which cannot set the
+// Authorization header, pass the access token in the accessToken query
+// parameter and open a new stream with a fresh one once it expires.
+func SubscribeToChanges(c *gin.Context) {
+	var reqParams subscribeToChangesParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	for _, listId := range reqParams.ListIds {
+		verifyListErr := verifyUserReadPerms(listId, userId)
+		if verifyListErr != nil {
+			c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+				Success: false,
+				Error:   verifyListErr.Error(),
+			})
+			return
+		}
+	}
+
+	subscription := eventService.Subscribe(userId, reqParams.ListIds)
+	defer eventService.Unsubscribe(subscription)
+
+	heartbeat := time.NewTicker(HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return false
+			}
+
+			switch event.Type {
+			case interfaces.CHANGE_LIST_DELETED:
+				eventService.Leave(subscription, event.ListId)
+			case interfaces.CHANGE_LIST_UPDATED, interfaces.CHANGE_LIST_MEMBERS:
+				// Privacy and membership changes may have taken the list away
+				// from the user.
+				if verifyUserReadPerms(event.ListId, userId) != nil {
+					eventService.Leave(subscription, event.ListId)
+					event = interfaces.ChangeEvent{
+						Type:      interfaces.CHANGE_LIST_ACCESS_REVOKED,
+						ListId:    event.ListId,
+						Actor:     event.Actor,
+						Timestamp: event.Timestamp,
+					}
+				}
+			}
+
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			// The stream must not outlive the credentials it was opened
+			// with, so that revoked sessions and tokens stop receiving
+			// changes. Clients reconnect with a fresh access token.
+			_, authErr := validator.AuthenticateWithQueryToken(c)
+			if authErr != nil {
+				return false
+			}
+
+			_, writeErr := io.WriteString(w, ": heartbeat\n\n")
+			return writeErr == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	filterService "github.com/beebeeoii/do-gether/services/filter"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	queryService "github.com/beebeeoii/do-gether/services/query"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+type createFilterBody struct {
+	Name  string `json:"name" validate:"min=1,max=40,required"`
+	Query string `json:"query" validate:"min=1,max=500,required"`
+}
+
+type editFilterBody struct {
+	Id    string `json:"id" validate:"min=1,max=20,required"`
+	Name  string `json:"name" validate:"min=1,max=40,required"`
+	Query string `json:"query" validate:"min=1,max=500,required"`
+}
+
+type deleteFilterParams struct {
+	Id string `form:"filterId" validate:"required,min=1,max=20"`
+}
+
+type retrieveFilterTasksParams struct {
+	Id       string `form:"filterId" validate:"required,min=1,max=20"`
+	Timezone string `form:"timezone" validate:"max=64"` // IANA name, defaults to UTC
+}
+
+// validateQuery makes sure a filter compiles before it is saved.
+func validateQuery(query string) error {
+	_, compileErr := queryService.Compile(query, queryService.CompileOptions{
+		Now:        time.Now(),
+		FirstParam: 2,
+	})
+
+	return compileErr
+}
+
+func CreateFilter(c *gin.Context) {
+	var requestBody createFilterBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	validateQueryErr := validateQuery(requestBody.Query)
+	if validateQueryErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validateQueryErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	newFilter, createFilterErr := filterService.CreateFilter(db.Database, userId, requestBody.Name, requestBody.Query)
+	if createFilterErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   createFilterErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.CreateFilterResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: newFilter,
+	})
+}
+
+func EditFilter(c *gin.Context) {
+	var requestBody editFilterBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	validateQueryErr := validateQuery(requestBody.Query)
+	if validateQueryErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validateQueryErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	filter, retrieveFilterErr := filterService.RetrieveFilterById(requestBody.Id)
+	if retrieveFilterErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveFilterErr.Error(),
+		})
+		return
+	}
+
+	if filter.Owner != userId {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("access denied").Error(),
+		})
+		return
+	}
+
+	updatedFilter, editFilterErr := filterService.EditFilter(db.Database, requestBody.Id, requestBody.Name, requestBody.Query)
+	if editFilterErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   editFilterErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.EditFilterResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedFilter,
+	})
+}
+
+func DeleteFilter(c *gin.Context) {
+	var reqParams deleteFilterParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	filter, retrieveFilterErr := filterService.RetrieveFilterById(reqParams.Id)
+	if retrieveFilterErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveFilterErr.Error(),
+		})
+		return
+	}
+
+	if filter.Owner != userId {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("access denied").Error(),
+		})
+		return
+	}
+
+	deletedFilter, deleteFilterErr := filterService.DeleteFilter(db.Database, reqParams.Id)
+	if deleteFilterErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   deleteFilterErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.DeleteFilterResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: deletedFilter,
+	})
+}
+
+func RetrieveFilters(c *gin.Context) {
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	filters, retrieveFiltersErr := filterService.RetrieveFiltersByOwner(userId)
+	if retrieveFiltersErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveFiltersErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveFiltersResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: filters,
+	})
+}
+
+// RetrieveFilterTasks runs a saved filter as a virtual list over every list
+// the user owns or is a member of.
+func RetrieveFilterTasks(c *gin.Context) {
+	var reqParams retrieveFilterTasksParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	location, loadLocationErr := time.LoadLocation(reqParams.Timezone)
+	if loadLocationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("invalid timezone").Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	filter, retrieveFilterErr := filterService.RetrieveFilterById(reqParams.Id)
+	if retrieveFilterErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveFilterErr.Error(),
+		})
+		return
+	}
+
+	if filter.Owner != userId {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("access denied").Error(),
+		})
+		return
+	}
+
+	compiledQuery, compileErr := queryService.Compile(filter.Query, queryService.CompileOptions{
+		UserId:     userId,
+		Now:        time.Now().In(location),
+		FirstParam: 2,
+	})
+	if compileErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   compileErr.Error(),
+		})
+		return
+	}
+
+	lists, retrieveListsErr := listService.RetrieveListsByUserId(userId, userId, false)
+	if retrieveListsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListsErr.Error(),
+		})
+		return
+	}
+
+	listIds := []string{}
+	for _, list := range lists {
+		listIds = append(listIds, list.Id)
+	}
+
+	tasks, retrieveTasksErr := taskService.RetrieveTasksByQuery(listIds, compiledQuery)
+	if retrieveTasksErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveTasksErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveTasksResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: tasks,
+	})
+}
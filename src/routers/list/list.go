@@ -1,13 +1,15 @@
 package router
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/beebeeoii/do-gether/db"
 	"github.com/beebeeoii/do-gether/interfaces"
 	validator "github.com/beebeeoii/do-gether/routers/validator"
+	eventService "github.com/beebeeoii/do-gether/services/event"
 	listService "github.com/beebeeoii/do-gether/services/list"
-	taskService "github.com/beebeeoii/do-gether/services/task"
 	userService "github.com/beebeeoii/do-gether/services/user"
 	"github.com/gin-gonic/gin"
 )
@@ -29,12 +31,27 @@ type editListMembersBody struct {
 	Members []string `json:"members" validate:"required"`
 }
 
+type archiveListBody struct {
+	Id       string `json:"id" validate:"min=1,max=20,required"`
+	Archived bool   `json:"archived"`
+}
+
+type editListAutoArchiveBody struct {
+	Id              string `json:"id" validate:"min=1,max=20,required"`
+	AutoArchiveDays int    `json:"autoArchiveDays" validate:"min=-1,max=3650"`
+}
+
 type deleteListParams struct {
 	Id string `form:"listId" validate:"required,min=1,max=20"`
 }
 
+type restoreListBody struct {
+	Id string `json:"id" validate:"min=1,max=20,required"`
+}
+
 type retrieveListsByUserIdParams struct {
-	Id string `form:"userId" validate:"required,min=1,max=20"`
+	Id              string `form:"userId" validate:"required,min=1,max=20"`
+	IncludeArchived bool   `form:"includeArchived"`
 }
 
 type retrieveListMembersParams struct {
@@ -45,20 +62,31 @@ type retrieveListOwnerParams struct {
 	ListId string `form:"listId" validate:"required,min=1,max=20"`
 }
 
-const (
-	USER_ID_HEADER_KEY = "id"
-)
-
-func CreateList(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+// respondWithCurrentList rejects an edit based on an outdated version of a
+// list and sends back the current copy, so that the client can merge. status
+// is 412 if the If-Match header was already outdated, or 409 if another edit
+// got in between the check and the write.
+func respondWithCurrentList(c *gin.Context, status int, listId string) {
+	currentList, retrieveListErr := listService.RetrieveListById(listId)
+	if retrieveListErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
 			Success: false,
-			Error:   authDataValidationErr.Error(),
+			Error:   retrieveListErr.Error(),
 		})
 		return
 	}
 
+	c.Header("ETag", validator.ETag(currentList.Version))
+	c.JSON(status, interfaces.EditListResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: false,
+			Error:   db.ErrVersionConflict.Error(),
+		},
+		Data: currentList,
+	})
+}
+
+func CreateList(c *gin.Context) {
 	var requestBody createListBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -79,7 +107,7 @@ func CreateList(c *gin.Context) {
 		return
 	}
 
-	if requestBody.Owner != c.GetHeader(USER_ID_HEADER_KEY) {
+	if requestBody.Owner != c.GetString(validator.USER_ID_CONTEXT_KEY) {
 		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
 			Success: false,
 			Error:   fmt.Errorf("list owner id mismatch with current account id").Error(),
@@ -87,7 +115,7 @@ func CreateList(c *gin.Context) {
 		return
 	}
 
-	newList, createListErr := listService.CreateList(requestBody.Name, requestBody.Owner, requestBody.Private)
+	newList, createListErr := listService.CreateList(db.Database, requestBody.Name, requestBody.Owner, requestBody.Private)
 	if createListErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -96,6 +124,7 @@ func CreateList(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", validator.ETag(newList.Version))
 	c.JSON(http.StatusOK, interfaces.CreateListResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
@@ -106,15 +135,6 @@ func CreateList(c *gin.Context) {
 }
 
 func EditList(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var requestBody editListBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -135,7 +155,16 @@ func EditList(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	list, retrieveListErr := listService.RetrieveListById(requestBody.Id)
 	if retrieveListErr != nil {
@@ -154,8 +183,18 @@ func EditList(c *gin.Context) {
 		return
 	}
 
-	updatedList, editListErr := listService.EditList(requestBody.Id, requestBody.Name, requestBody.Private)
+	if list.Version != ifMatchVersion {
+		respondWithCurrentList(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
+	updatedList, editListErr := listService.EditList(db.Database, requestBody.Id, requestBody.Name, requestBody.Private, ifMatchVersion)
 	if editListErr != nil {
+		if errors.Is(editListErr, db.ErrVersionConflict) {
+			respondWithCurrentList(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
 			Error:   editListErr.Error(),
@@ -163,6 +202,9 @@ func EditList(c *gin.Context) {
 		return
 	}
 
+	eventService.PublishList(interfaces.CHANGE_LIST_UPDATED, userId, updatedList)
+
+	c.Header("ETag", validator.ETag(updatedList.Version))
 	c.JSON(http.StatusOK, interfaces.EditListResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
@@ -173,15 +215,6 @@ func EditList(c *gin.Context) {
 }
 
 func EditListMembers(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var requestBody editListMembersBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -202,7 +235,16 @@ func EditListMembers(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	list, retrieveListErr := listService.RetrieveListById(requestBody.Id)
 	if retrieveListErr != nil {
@@ -232,8 +274,18 @@ func EditListMembers(c *gin.Context) {
 		}
 	}
 
-	updatedList, editListMembersErr := listService.EditListMembers(requestBody.Id, requestBody.Members)
+	if list.Version != ifMatchVersion {
+		respondWithCurrentList(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
+	updatedList, editListMembersErr := listService.EditListMembers(db.Database, requestBody.Id, requestBody.Members, ifMatchVersion)
 	if editListMembersErr != nil {
+		if errors.Is(editListMembersErr, db.ErrVersionConflict) {
+			respondWithCurrentList(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
 			Error:   editListMembersErr.Error(),
@@ -241,6 +293,9 @@ func EditListMembers(c *gin.Context) {
 		return
 	}
 
+	eventService.PublishListMembers(userId, updatedList, list.Members)
+
+	c.Header("ETag", validator.ETag(updatedList.Version))
 	c.JSON(http.StatusOK, interfaces.EditListResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
@@ -250,16 +305,167 @@ func EditListMembers(c *gin.Context) {
 	})
 }
 
-func DeleteList(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
+func ArchiveList(c *gin.Context) {
+	var requestBody archiveListBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	list, retrieveListErr := listService.RetrieveListById(requestBody.Id)
+	if retrieveListErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListErr.Error(),
+		})
+		return
+	}
+
+	if !validator.HasListEditPermission(list, userId) {
 		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
 			Success: false,
-			Error:   authDataValidationErr.Error(),
+			Error:   fmt.Errorf("access denied").Error(),
 		})
 		return
 	}
 
+	if list.Version != ifMatchVersion {
+		respondWithCurrentList(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
+	updatedList, archiveListErr := listService.ArchiveList(db.Database, requestBody.Id, requestBody.Archived, ifMatchVersion)
+	if archiveListErr != nil {
+		if errors.Is(archiveListErr, db.ErrVersionConflict) {
+			respondWithCurrentList(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   archiveListErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishList(interfaces.CHANGE_LIST_UPDATED, userId, updatedList)
+
+	c.Header("ETag", validator.ETag(updatedList.Version))
+	c.JSON(http.StatusOK, interfaces.ArchiveListResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedList,
+	})
+}
+
+func EditListAutoArchive(c *gin.Context) {
+	var requestBody editListAutoArchiveBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	list, retrieveListErr := listService.RetrieveListById(requestBody.Id)
+	if retrieveListErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListErr.Error(),
+		})
+		return
+	}
+
+	if !validator.HasListEditPermission(list, userId) {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("access denied").Error(),
+		})
+		return
+	}
+
+	if list.Version != ifMatchVersion {
+		respondWithCurrentList(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
+	updatedList, editListErr := listService.EditListAutoArchive(db.Database, requestBody.Id, requestBody.AutoArchiveDays, ifMatchVersion)
+	if editListErr != nil {
+		if errors.Is(editListErr, db.ErrVersionConflict) {
+			respondWithCurrentList(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   editListErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishList(interfaces.CHANGE_LIST_UPDATED, userId, updatedList)
+
+	c.Header("ETag", validator.ETag(updatedList.Version))
+	c.JSON(http.StatusOK, interfaces.EditListResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedList,
+	})
+}
+
+func DeleteList(c *gin.Context) {
 	var reqParams deleteListParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -280,7 +486,7 @@ func DeleteList(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	ownerId, retrieveOwnerIdErr := listService.RetrieveOwnerIdByListId(reqParams.Id)
 	if retrieveOwnerIdErr != nil {
@@ -299,7 +505,7 @@ func DeleteList(c *gin.Context) {
 		return
 	}
 
-	deletedList, deleteListErr := listService.DeleteList(reqParams.Id)
+	deletedList, deleteListErr := listService.DeleteList(db.Database, userId, reqParams.Id)
 	if deleteListErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -308,14 +514,7 @@ func DeleteList(c *gin.Context) {
 		return
 	}
 
-	deleteTasksErr := taskService.DeleteTasksFromList(reqParams.Id)
-	if deleteTasksErr != nil {
-		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
-			Success: false,
-			Error:   deleteTasksErr.Error(),
-		})
-		return
-	}
+	eventService.PublishList(interfaces.CHANGE_LIST_DELETED, userId, deletedList)
 
 	c.JSON(http.StatusOK, interfaces.DeleteListResponse{
 		BaseResponse: interfaces.BaseResponse{
@@ -326,16 +525,68 @@ func DeleteList(c *gin.Context) {
 	})
 }
 
-func RetrieveListsByUserId(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
+func RestoreList(c *gin.Context) {
+	var requestBody restoreListBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	deletedList, retrieveListErr := listService.RetrieveDeletedListById(requestBody.Id)
+	if retrieveListErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListErr.Error(),
+		})
+		return
+	}
+
+	if deletedList.DeletedBy != userId {
 		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
 			Success: false,
-			Error:   authDataValidationErr.Error(),
+			Error:   fmt.Errorf("access denied").Error(),
 		})
 		return
 	}
 
+	restoredList, restoreListErr := listService.RestoreList(db.Database, requestBody.Id)
+	if restoreListErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   restoreListErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishListRestored(userId, restoredList)
+
+	c.Header("ETag", validator.ETag(restoredList.Version))
+	c.JSON(http.StatusOK, interfaces.RestoreListResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: restoredList,
+	})
+}
+
+func RetrieveListsByUserId(c *gin.Context) {
 	var reqParams retrieveListsByUserIdParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -356,9 +607,9 @@ func RetrieveListsByUserId(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
-	lists, retrieveListsErr := listService.RetrieveListsByUserId(reqParams.Id, userId)
+	lists, retrieveListsErr := listService.RetrieveListsByUserId(reqParams.Id, userId, reqParams.IncludeArchived)
 	if retrieveListsErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -377,15 +628,6 @@ func RetrieveListsByUserId(c *gin.Context) {
 }
 
 func RetrieveListMembers(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var reqParams retrieveListMembersParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -406,7 +648,7 @@ func RetrieveListMembers(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	list, retrieveListErr := listService.RetrieveListById(reqParams.ListId)
 	if retrieveListErr != nil {
@@ -452,15 +694,6 @@ func RetrieveListMembers(c *gin.Context) {
 }
 
 func RetrieveListOwner(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var reqParams retrieveListOwnerParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -481,7 +714,7 @@ func RetrieveListOwner(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	list, retrieveListErr := listService.RetrieveListById(reqParams.ListId)
 	if retrieveListErr != nil {
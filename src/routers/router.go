@@ -1,11 +1,23 @@
 package router
 
 import (
+	"log"
+	"os"
+	"strconv"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/beebeeoii/do-gether/interfaces"
+	attachment "github.com/beebeeoii/do-gether/routers/attachment"
 	auth "github.com/beebeeoii/do-gether/routers/auth"
+	comment "github.com/beebeeoii/do-gether/routers/comment"
+	event "github.com/beebeeoii/do-gether/routers/event"
+	filter "github.com/beebeeoii/do-gether/routers/filter"
 	list "github.com/beebeeoii/do-gether/routers/list"
+	search "github.com/beebeeoii/do-gether/routers/search"
+	tag "github.com/beebeeoii/do-gether/routers/tag"
 	task "github.com/beebeeoii/do-gether/routers/task"
+	trash "github.com/beebeeoii/do-gether/routers/trash"
 	user "github.com/beebeeoii/do-gether/routers/user"
 	validator "github.com/beebeeoii/do-gether/routers/validator"
 )
@@ -16,42 +28,132 @@ func Init(address string) {
 
 	validator.Init()
 
-	router.GET("/user/authenticate", auth.AuthenticateUser)
+	router.POST(auth.LOG_IN_ROUTE, auth.LogIn)
+	if isLegacyLoginEnabled() {
+		router.GET("/user/authenticate", auth.AuthenticateUser)
+	}
+	router.POST("/user/refresh", auth.RefreshTokens)
+	router.POST("/user/logout", auth.LogOut)
 	router.POST("/user", user.Register)
-	router.GET("/user/:id", user.RetrieveUserById)
-	router.GET("/user/friend", user.FindUserByUsername)
-	router.GET("/user/friend/all", user.RetrieveAllUserFriends)
-	router.DELETE("/user/friend", user.RemoveFriend)
-	router.POST("/user/friend/sendReq", user.SendFriendReq)
-	router.POST("/user/friend/acceptReq", user.AcceptFriendReq)
-	router.DELETE("/user/friend/deleteReq", user.RemoveFriendRequest)
-
-	router.POST("/list", list.CreateList)
-	router.DELETE("/list", list.DeleteList)
-	router.POST("/list/edit", list.EditList)
-	router.POST("/list/editMembers", list.EditListMembers)
-	router.GET("/list", list.RetrieveListsByUserId)
-	router.GET("/list/members", list.RetrieveListMembers)
-	router.GET("/list/owner", list.RetrieveListOwner)
-
-	router.POST("/task", task.CreateTask)
-	router.DELETE("/task", task.DeleteTask)
-	router.POST("/task/edit", task.EditTask)
-	router.POST("/task/edit/move", task.MoveTask)
-	router.POST("/task/editCompleted", task.EditTaskCompleted)
-	router.POST("/task/reorder", task.ReorderTasks)
-	router.GET("/task", task.RetrieveTasksByListId)
-	router.GET("/task/tagSuggestion", task.RetrieveTagSuggestion)
+
+	authorized := router.Group("/")
+	authorized.Use(validator.AuthMiddleware())
+
+	// Personal access tokens cannot manage accounts, sessions or other tokens.
+	userRoutes := authorized.Group("/user", validator.RequireScope("", ""))
+	userRoutes.GET("/:id", user.RetrieveUserById)
+	userRoutes.GET("/friend", user.FindUserByUsername)
+	userRoutes.GET("/friend/all", user.RetrieveAllUserFriends)
+	userRoutes.DELETE("/friend", user.RemoveFriend)
+	userRoutes.POST("/friend/sendReq", user.SendFriendReq)
+	userRoutes.POST("/friend/acceptReq", user.AcceptFriendReq)
+	userRoutes.DELETE("/friend/deleteReq", user.RemoveFriendRequest)
+	userRoutes.GET("/session/all", auth.RetrieveSessions)
+	userRoutes.DELETE("/session", auth.RevokeSession)
+	userRoutes.POST("/token", auth.CreatePersonalAccessToken)
+	userRoutes.GET("/token/all", auth.RetrievePersonalAccessTokens)
+	userRoutes.DELETE("/token", auth.DeletePersonalAccessToken)
+
+	listRoutes := authorized.Group("/list", validator.RequireScope(interfaces.SCOPE_LISTS_READ, interfaces.SCOPE_LISTS_ADMIN))
+	listRoutes.POST("", list.CreateList)
+	listRoutes.DELETE("", list.DeleteList)
+	listRoutes.POST("/edit", list.EditList)
+	listRoutes.POST("/editMembers", list.EditListMembers)
+	listRoutes.POST("/restore", list.RestoreList)
+	listRoutes.POST("/archive", list.ArchiveList)
+	listRoutes.POST("/editAutoArchive", list.EditListAutoArchive)
+	listRoutes.GET("", list.RetrieveListsByUserId)
+	listRoutes.GET("/members", list.RetrieveListMembers)
+	listRoutes.GET("/owner", list.RetrieveListOwner)
+
+	taskScope := validator.RequireScope(interfaces.SCOPE_TASKS_READ, interfaces.SCOPE_TASKS_WRITE)
+
+	taskRoutes := authorized.Group("/task", taskScope)
+	taskRoutes.POST("", task.CreateTask)
+	taskRoutes.DELETE("", task.DeleteTask)
+	taskRoutes.POST("/edit", task.EditTask)
+	taskRoutes.POST("/edit/move", task.MoveTask)
+	taskRoutes.POST("/restore", task.RestoreTask)
+	taskRoutes.POST("/archive", task.ArchiveTask)
+	taskRoutes.POST("/editCompleted", task.EditTaskCompleted)
+	taskRoutes.POST("/editAssignees", task.EditTaskAssignees)
+	taskRoutes.POST("/dependency", task.CreateTaskDependency)
+	taskRoutes.DELETE("/dependency", task.DeleteTaskDependency)
+	taskRoutes.POST("/reorder", task.ReorderTasks)
+	taskRoutes.GET("", task.RetrieveTasksByListId)
+	taskRoutes.GET("/tagSuggestion", task.RetrieveTagSuggestion)
+	taskRoutes.GET("/assigned", task.RetrieveAssignedTasks)
+	taskRoutes.GET("/history", task.RetrieveTaskHistory)
+	taskRoutes.GET("/view", task.RetrieveTaskView)
+	taskRoutes.GET("/query", task.RetrieveTasksByQuery)
+	taskRoutes.POST("/subtask", task.CreateSubtask)
+	taskRoutes.DELETE("/subtask", task.DeleteSubtask)
+	taskRoutes.POST("/subtask/edit", task.EditSubtask)
+	taskRoutes.POST("/subtask/editCompleted", task.EditSubtaskCompleted)
+	taskRoutes.POST("/subtask/reorder", task.ReorderSubtasks)
+	taskRoutes.GET("/subtask", task.RetrieveSubtasksByTaskId)
+
+	commentRoutes := authorized.Group("/comment", taskScope)
+	commentRoutes.POST("", comment.CreateComment)
+	commentRoutes.DELETE("", comment.DeleteComment)
+	commentRoutes.POST("/edit", comment.EditComment)
+	commentRoutes.GET("", comment.RetrieveCommentsByTaskId)
+
+	attachmentRoutes := authorized.Group("/attachment", taskScope)
+	attachmentRoutes.POST("", attachment.CreateAttachment)
+	attachmentRoutes.DELETE("", attachment.DeleteAttachment)
+	attachmentRoutes.GET("", attachment.DownloadAttachment)
+	attachmentRoutes.GET("/all", attachment.RetrieveAttachmentsByTaskId)
+
+	filterRoutes := authorized.Group("/filter", taskScope)
+	filterRoutes.POST("", filter.CreateFilter)
+	filterRoutes.DELETE("", filter.DeleteFilter)
+	filterRoutes.POST("/edit", filter.EditFilter)
+	filterRoutes.GET("/all", filter.RetrieveFilters)
+	filterRoutes.GET("/tasks", filter.RetrieveFilterTasks)
+
+	tagRoutes := authorized.Group("/tag", taskScope)
+	tagRoutes.GET("", tag.RetrieveListTags)
+	tagRoutes.DELETE("", tag.DeleteTag)
+	tagRoutes.POST("/edit", tag.EditTag)
+	tagRoutes.POST("/rename", tag.RenameTag)
+	tagRoutes.POST("/merge", tag.MergeTags)
+
+	authorized.GET("/trash", taskScope, trash.RetrieveTrash)
+
+	authorized.GET("/search", taskScope, search.Search)
+
+	// EventSource cannot set the Authorization header, so the stream of
+	// changes also takes the access token in the query string.
+	router.GET("/events", validator.QueryTokenAuthMiddleware(), taskScope, event.SubscribeToChanges)
 
 	router.Run(address)
 }
 
+// isLegacyLoginEnabled reads LEGACY_LOGIN_ENABLED, which keeps the deprecated
+// GET /user/authenticate around for clients that still log in with the
+// credentials in the query string.
+func isLegacyLoginEnabled() bool {
+	LEGACY_LOGIN_ENABLED := os.Getenv("LEGACY_LOGIN_ENABLED")
+	if LEGACY_LOGIN_ENABLED == "" {
+		return false
+	}
+
+	isEnabled, parseErr := strconv.ParseBool(LEGACY_LOGIN_ENABLED)
+	if parseErr != nil {
+		log.Fatalf("invalid legacy login flag %q", LEGACY_LOGIN_ENABLED)
+	}
+
+	return isEnabled
+}
+
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, id")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag")
 		c.Header("Access-Control-Allow-Methods", "POST,HEAD,PATCH,OPTIONS,GET,PUT,DELETE")
 
 		if c.Request.Method == "OPTIONS" {
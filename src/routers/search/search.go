@@ -0,0 +1,114 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	searchService "github.com/beebeeoii/do-gether/services/search"
+	userService "github.com/beebeeoii/do-gether/services/user"
+	"github.com/gin-gonic/gin"
+)
+
+type searchParams struct {
+	Query string `form:"query" validate:"required,min=1,max=200"`
+	Limit int    `form:"limit" validate:"min=0,max=100"`
+}
+
+const (
+	DEFAULT_SEARCH_LIMIT = 20
+)
+
+// retrieveReadableListIds returns the lists the user owns or is a member of,
+// together with the public lists of their friends.
+func retrieveReadableListIds(userId string) ([]string, error) {
+	listIds := []string{}
+	checkedListIds := make(map[string]bool)
+
+	ownerIds := []string{userId}
+	friendIds, retrieveFriendsErr := userService.RetrieveFriends(userId)
+	if retrieveFriendsErr != nil {
+		return listIds, retrieveFriendsErr
+	}
+	ownerIds = append(ownerIds, friendIds...)
+
+	for _, ownerId := range ownerIds {
+		lists, retrieveListsErr := listService.RetrieveListsByUserId(ownerId, userId, false)
+		if retrieveListsErr != nil {
+			return listIds, retrieveListsErr
+		}
+
+		for _, basicList := range lists {
+			if checkedListIds[basicList.Id] {
+				continue
+			}
+			checkedListIds[basicList.Id] = true
+
+			list, retrieveListErr := listService.RetrieveListById(basicList.Id)
+			if retrieveListErr != nil {
+				return listIds, retrieveListErr
+			}
+
+			if validator.HasListReadWritePermission(list, userId) {
+				listIds = append(listIds, list.Id)
+			}
+		}
+	}
+
+	return listIds, nil
+}
+
+func Search(c *gin.Context) {
+	var reqParams searchParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	if reqParams.Limit == 0 {
+		reqParams.Limit = DEFAULT_SEARCH_LIMIT
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	listIds, retrieveListIdsErr := retrieveReadableListIds(userId)
+	if retrieveListIdsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListIdsErr.Error(),
+		})
+		return
+	}
+
+	results, searchErr := searchService.Search(listIds, reqParams.Query, reqParams.Limit)
+	if searchErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   searchErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.SearchResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: results,
+	})
+}
@@ -0,0 +1,338 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	eventService "github.com/beebeeoii/do-gether/services/event"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	tagService "github.com/beebeeoii/do-gether/services/tag"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+type retrieveListTagsParams struct {
+	ListId string `form:"listId" validate:"required,min=1,max=20"`
+}
+
+type editTagBody struct {
+	ListId      string `json:"listId" validate:"min=1,max=20,required"`
+	Name        string `json:"name" validate:"min=1,max=60,required"`
+	Color       string `json:"color" validate:"omitempty,hexcolor,max=7"`
+	Description string `json:"description" validate:"max=200"`
+}
+
+type renameTagBody struct {
+	ListId  string `json:"listId" validate:"min=1,max=20,required"`
+	Name    string `json:"name" validate:"min=1,max=60,required"`
+	NewName string `json:"newName" validate:"min=1,max=60,required"`
+}
+
+type mergeTagsBody struct {
+	ListId string   `json:"listId" validate:"min=1,max=20,required"`
+	Names  []string `json:"names" validate:"min=1,max=50,required,dive,min=1,max=60"`
+	Into   string   `json:"into" validate:"min=1,max=60,required"`
+}
+
+type deleteTagParams struct {
+	ListId string `form:"listId" validate:"required,min=1,max=20"`
+	Name   string `form:"name" validate:"required,min=1,max=60"`
+}
+
+func verifyUserWritePerms(listId string, userId string) error {
+	list, retrieveListErr := listService.RetrieveListById(listId)
+	if retrieveListErr != nil {
+		return retrieveListErr
+	}
+
+	if !validator.HasListReadWritePermission(list, userId) {
+		return fmt.Errorf("access denied")
+	}
+
+	return nil
+}
+
+// publishTagsRewritten announces every task whose tags were rewritten by a
+// rename, merge or deletion of a tag.
+func publishTagsRewritten(actorId string, updatedTasks []interfaces.Task) {
+	for _, task := range updatedTasks {
+		eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, actorId, task)
+	}
+}
+
+func RetrieveListTags(c *gin.Context) {
+	var reqParams retrieveListTagsParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	verifyErr := verifyUserWritePerms(reqParams.ListId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	tags, retrieveTagsErr := taskService.RetrieveTagsWithUsageByListId(reqParams.ListId)
+	if retrieveTagsErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveTagsErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveListTagsResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: tags,
+	})
+}
+
+func EditTag(c *gin.Context) {
+	var requestBody editTagBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+	requestBody.Name = tagService.Normalise(requestBody.Name)
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	verifyErr := verifyUserWritePerms(requestBody.ListId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	tag, editTagErr := taskService.EditTag(db.Database, interfaces.TagEditionData{
+		ListId:      requestBody.ListId,
+		Name:        requestBody.Name,
+		Color:       requestBody.Color,
+		Description: requestBody.Description,
+	})
+	if editTagErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   editTagErr.Error(),
+		})
+		return
+	}
+
+	// Tag colours and descriptions are not part of any task, so subscribers
+	// are told that the list changed and refetch its tags.
+	list, retrieveListErr := listService.RetrieveListById(requestBody.ListId)
+	if retrieveListErr == nil {
+		eventService.PublishList(interfaces.CHANGE_LIST_UPDATED, userId, list)
+	}
+
+	c.JSON(http.StatusOK, interfaces.EditTagResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: tag,
+	})
+}
+
+func RenameTag(c *gin.Context) {
+	var requestBody renameTagBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+	requestBody.Name = tagService.Normalise(requestBody.Name)
+	requestBody.NewName = tagService.Normalise(requestBody.NewName)
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	verifyErr := verifyUserWritePerms(requestBody.ListId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	updatedTasks, renameTagErr := taskService.RenameTag(db.Database, userId, requestBody.ListId, requestBody.Name, requestBody.NewName)
+	if renameTagErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   renameTagErr.Error(),
+		})
+		return
+	}
+
+	publishTagsRewritten(userId, updatedTasks)
+
+	c.JSON(http.StatusOK, interfaces.RenameTagResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedTasks,
+	})
+}
+
+func MergeTags(c *gin.Context) {
+	var requestBody mergeTagsBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+	requestBody.Names = tagService.NormaliseAll(requestBody.Names)
+	requestBody.Into = tagService.Normalise(requestBody.Into)
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	verifyErr := verifyUserWritePerms(requestBody.ListId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	updatedTasks, mergeTagsErr := taskService.MergeTags(db.Database, userId, requestBody.ListId, requestBody.Names, requestBody.Into)
+	if mergeTagsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   mergeTagsErr.Error(),
+		})
+		return
+	}
+
+	publishTagsRewritten(userId, updatedTasks)
+
+	c.JSON(http.StatusOK, interfaces.MergeTagsResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedTasks,
+	})
+}
+
+func DeleteTag(c *gin.Context) {
+	var reqParams deleteTagParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+	reqParams.Name = tagService.Normalise(reqParams.Name)
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	verifyErr := verifyUserWritePerms(reqParams.ListId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	updatedTasks, deleteTagErr := taskService.DeleteTag(db.Database, userId, reqParams.ListId, reqParams.Name)
+	if deleteTagErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   deleteTagErr.Error(),
+		})
+		return
+	}
+
+	publishTagsRewritten(userId, updatedTasks)
+
+	c.JSON(http.StatusOK, interfaces.DeleteTagResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedTasks,
+	})
+}
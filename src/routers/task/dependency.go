@@ -0,0 +1,166 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+type createTaskDependencyBody struct {
+	TaskId    string `json:"taskId" validate:"min=1,max=20,required"`
+	BlockedBy string `json:"blockedBy" validate:"min=1,max=20,required"`
+}
+
+type deleteTaskDependencyParams struct {
+	TaskId    string `form:"taskId" validate:"required,min=1,max=20"`
+	BlockedBy string `form:"blockedBy" validate:"required,min=1,max=20"`
+}
+
+func verifyUserTaskWritePerms(taskId string, userId string) error {
+	listId, retrieveListIdErr := taskService.RetrieveListIdByTaskId(taskId)
+	if retrieveListIdErr != nil {
+		return retrieveListIdErr
+	}
+
+	return verifyUserWritePerms(listId, userId)
+}
+
+func CreateTaskDependency(c *gin.Context) {
+	var requestBody createTaskDependencyBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	for _, taskId := range []string{requestBody.TaskId, requestBody.BlockedBy} {
+		verifyErr := verifyUserTaskWritePerms(taskId, userId)
+		if verifyErr != nil {
+			c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+				Success: false,
+				Error:   verifyErr.Error(),
+			})
+			return
+		}
+	}
+
+	var dependency interfaces.TaskDependency
+	var hasCycle bool
+
+	transactErr := db.Transact(db.Database, func(tx db.Executor) error {
+		lockErr := taskService.LockTaskDependencies(tx)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		var cycleCheckErr error
+		hasCycle, cycleCheckErr = taskService.WouldCreateDependencyCycle(tx, requestBody.TaskId, requestBody.BlockedBy)
+		if cycleCheckErr != nil || hasCycle {
+			return cycleCheckErr
+		}
+
+		var createDependencyErr error
+		dependency, createDependencyErr = taskService.CreateTaskDependency(tx, interfaces.TaskDependency{
+			TaskId:    requestBody.TaskId,
+			BlockedBy: requestBody.BlockedBy,
+		})
+
+		return createDependencyErr
+	})
+	if transactErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   transactErr.Error(),
+		})
+		return
+	}
+
+	if hasCycle {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("dependency would create a cycle").Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.TaskDependencyResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: dependency,
+	})
+}
+
+func DeleteTaskDependency(c *gin.Context) {
+	var reqParams deleteTaskDependencyParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	verifyErr := verifyUserTaskWritePerms(reqParams.TaskId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	deletedDependency, deleteDependencyErr := taskService.DeleteTaskDependency(db.Database, interfaces.TaskDependency{
+		TaskId:    reqParams.TaskId,
+		BlockedBy: reqParams.BlockedBy,
+	})
+	if deleteDependencyErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   deleteDependencyErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.TaskDependencyResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: deletedDependency,
+	})
+}
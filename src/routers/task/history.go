@@ -0,0 +1,106 @@
+package router
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	historyService "github.com/beebeeoii/do-gether/services/history"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+type retrieveTaskHistoryParams struct {
+	TaskId string `form:"taskId" validate:"required,min=1,max=20"`
+}
+
+// retrieveListIdOfTaskWithHistory returns the list a task lives in, or was
+// sent to the trash from. Purged tasks take their history with them.
+func retrieveListIdOfTaskWithHistory(taskId string) (string, error) {
+	listId, retrieveListIdErr := taskService.RetrieveListIdByTaskId(taskId)
+	if !errors.Is(retrieveListIdErr, sql.ErrNoRows) {
+		return listId, retrieveListIdErr
+	}
+
+	deletedTask, retrieveDeletedErr := taskService.RetrieveDeletedTaskById(taskId)
+
+	return deletedTask.ListId, retrieveDeletedErr
+}
+
+func RetrieveTaskHistory(c *gin.Context) {
+	var reqParams retrieveTaskHistoryParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	events, retrieveEventsErr := historyService.RetrieveTaskEvents(reqParams.TaskId)
+	if retrieveEventsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveEventsErr.Error(),
+		})
+		return
+	}
+
+	if len(events) == 0 {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("no history found for task").Error(),
+		})
+		return
+	}
+
+	listId, retrieveListIdErr := retrieveListIdOfTaskWithHistory(reqParams.TaskId)
+	if retrieveListIdErr != nil {
+		if errors.Is(retrieveListIdErr, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+				Success: false,
+				Error:   retrieveListIdErr.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListIdErr.Error(),
+		})
+		return
+	}
+
+	verifyErr := verifyUserWritePerms(listId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveTaskHistoryResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: events,
+	})
+}
@@ -0,0 +1,96 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	queryService "github.com/beebeeoii/do-gether/services/query"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+type retrieveTasksByQueryParams struct {
+	Query    string `form:"query" validate:"max=500"`
+	Timezone string `form:"timezone" validate:"max=64"` // IANA name, defaults to UTC
+}
+
+func RetrieveTasksByQuery(c *gin.Context) {
+	var reqParams retrieveTasksByQueryParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	location, loadLocationErr := time.LoadLocation(reqParams.Timezone)
+	if loadLocationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("invalid timezone").Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	compiledQuery, compileErr := queryService.Compile(reqParams.Query, queryService.CompileOptions{
+		UserId:     userId,
+		Now:        time.Now().In(location),
+		FirstParam: 2,
+	})
+	if compileErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   compileErr.Error(),
+		})
+		return
+	}
+
+	lists, retrieveListsErr := listService.RetrieveListsByUserId(userId, userId, false)
+	if retrieveListsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListsErr.Error(),
+		})
+		return
+	}
+
+	listIds := []string{}
+	for _, list := range lists {
+		listIds = append(listIds, list.Id)
+	}
+
+	tasks, retrieveTasksErr := taskService.RetrieveTasksByQuery(listIds, compiledQuery)
+	if retrieveTasksErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveTasksErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveTasksResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: tasks,
+	})
+}
@@ -0,0 +1,512 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	eventService "github.com/beebeeoii/do-gether/services/event"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+type createSubtaskBody struct {
+	TaskId   string `json:"taskId" validate:"min=1,max=20,required"`
+	ParentId string `json:"parentId" validate:"max=20"` // subtask to nest under, "" for a top-level subtask
+	Title    string `json:"title" validate:"required"`
+}
+
+type editSubtaskBody struct {
+	Id    string `json:"id" validate:"min=1,max=20,required"`
+	Title string `json:"title" validate:"required"`
+}
+
+type editSubtaskCompletedBody struct {
+	Id                 string `json:"id" validate:"min=1,max=20,required"`
+	Completed          bool   `json:"completed"`
+	AutoCompleteParent bool   `json:"autoCompleteParent"`
+	Timezone           string `json:"timezone" validate:"max=64"` // IANA name recurrences are expanded in, defaults to UTC
+}
+
+type reorderSubtaskBody struct {
+	Id           string `json:"id" validate:"min=1,max=20,required"`
+	NewListOrder int    `json:"newListOrder" validate:"min=0"`
+}
+
+type deleteSubtaskParams struct {
+	Id string `form:"subtaskId" validate:"required,min=1,max=20"`
+}
+
+type retrieveSubtasksByTaskIdParams struct {
+	TaskId string `form:"taskId" validate:"required,min=1,max=20"`
+}
+
+func retrieveListIdBySubtaskId(subtaskId string) (string, string, error) {
+	taskId, retrieveTaskIdErr := taskService.RetrieveTaskIdBySubtaskId(subtaskId)
+	if retrieveTaskIdErr != nil {
+		return "", "", retrieveTaskIdErr
+	}
+
+	listId, retrieveListIdErr := taskService.RetrieveListIdByTaskId(taskId)
+	if retrieveListIdErr != nil {
+		return taskId, "", retrieveListIdErr
+	}
+
+	return taskId, listId, nil
+}
+
+func CreateSubtask(c *gin.Context) {
+	var requestBody createSubtaskBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	listId, retrieveListIdErr := taskService.RetrieveListIdByTaskId(requestBody.TaskId)
+	if retrieveListIdErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListIdErr.Error(),
+		})
+		return
+	}
+
+	verifyErr := verifyUserWritePerms(listId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	if requestBody.ParentId != "" {
+		parentTaskId, retrieveTaskIdErr := taskService.RetrieveTaskIdBySubtaskId(requestBody.ParentId)
+		if retrieveTaskIdErr != nil || parentTaskId != requestBody.TaskId {
+			c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+				Success: false,
+				Error:   fmt.Errorf("parent subtask does not belong to the task").Error(),
+			})
+			return
+		}
+	}
+
+	newSubtask, updatedTask, createSubtaskErr := taskService.CreateSubtask(db.Database, userId, interfaces.SubtaskCreationData{
+		TaskId:   requestBody.TaskId,
+		ParentId: requestBody.ParentId,
+		Title:    requestBody.Title,
+	})
+	if createSubtaskErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   createSubtaskErr.Error(),
+		})
+		return
+	}
+
+	// Subscribers refetch the subtasks of updated tasks.
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, updatedTask)
+
+	c.JSON(http.StatusOK, interfaces.CreateSubtaskResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: newSubtask,
+	})
+}
+
+func EditSubtask(c *gin.Context) {
+	var requestBody editSubtaskBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	_, listId, retrieveListIdErr := retrieveListIdBySubtaskId(requestBody.Id)
+	if retrieveListIdErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListIdErr.Error(),
+		})
+		return
+	}
+
+	verifyErr := verifyUserWritePerms(listId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	updatedSubtask, updatedTask, editSubtaskErr := taskService.EditSubtask(db.Database, userId, interfaces.SubtaskEditionData{
+		Id:    requestBody.Id,
+		Title: requestBody.Title,
+	})
+	if editSubtaskErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   editSubtaskErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, updatedTask)
+
+	c.JSON(http.StatusOK, interfaces.EditSubtaskResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedSubtask,
+	})
+}
+
+func EditSubtaskCompleted(c *gin.Context) {
+	var requestBody editSubtaskCompletedBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	location, loadLocationErr := time.LoadLocation(requestBody.Timezone)
+	if loadLocationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("invalid timezone").Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	taskId, listId, retrieveListIdErr := retrieveListIdBySubtaskId(requestBody.Id)
+	if retrieveListIdErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListIdErr.Error(),
+		})
+		return
+	}
+
+	verifyErr := verifyUserWritePerms(listId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	var updatedSubtask interfaces.Subtask
+	var parentTask interfaces.Task
+	var nextOccurrence *interfaces.Task
+
+	// The subtask and its parent are completed together or not at all.
+	transactErr := db.Transact(db.Database, func(tx db.Executor) error {
+		var editSubtaskErr error
+		updatedSubtask, parentTask, editSubtaskErr = taskService.EditSubtaskCompleted(tx, userId, interfaces.SubtaskEditCompletedData{
+			Id:        requestBody.Id,
+			Completed: requestBody.Completed,
+		})
+		if editSubtaskErr != nil {
+			return editSubtaskErr
+		}
+
+		if !requestBody.Completed || !requestBody.AutoCompleteParent || parentTask.Completed {
+			return nil
+		}
+
+		allCompleted, checkCompletedErr := taskService.AreAllSubtasksCompleted(tx, taskId)
+		if checkCompletedErr != nil {
+			return checkCompletedErr
+		}
+
+		if !allCompleted {
+			return nil
+		}
+
+		completedTask, spawnedTask, editTaskErr := taskService.EditTaskCompleted(tx, userId, interfaces.TaskEditCompletedData{
+			Id:        taskId,
+			Completed: true,
+			Version:   interfaces.ANY_VERSION,
+		}, location)
+		if errors.Is(editTaskErr, taskService.ErrTaskBlocked) {
+			// Blocked parents are left open, and nothing was written yet.
+			return nil
+		}
+		if editTaskErr != nil {
+			return editTaskErr
+		}
+
+		parentTask = completedTask
+		nextOccurrence = spawnedTask
+
+		return nil
+	})
+	if transactErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   transactErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, parentTask)
+	if nextOccurrence != nil {
+		eventService.PublishTask(interfaces.CHANGE_TASK_CREATED, userId, *nextOccurrence)
+	}
+
+	c.JSON(http.StatusOK, interfaces.EditSubtaskCompletedResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: interfaces.SubtaskCompletedData{
+			Subtask:        updatedSubtask,
+			Task:           parentTask,
+			NextOccurrence: nextOccurrence,
+		},
+	})
+}
+
+func ReorderSubtasks(c *gin.Context) {
+	var requestBody reorderSubtaskBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	_, listId, retrieveListIdErr := retrieveListIdBySubtaskId(requestBody.Id)
+	if retrieveListIdErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListIdErr.Error(),
+		})
+		return
+	}
+
+	verifyErr := verifyUserWritePerms(listId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	reorderedSubtasks, updatedTask, reorderErr := taskService.ReorderSubtask(db.Database, userId, requestBody.Id, requestBody.NewListOrder)
+	if reorderErr != nil {
+		if errors.Is(reorderErr, taskService.ErrListOrderOutOfRange) {
+			c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+				Success: false,
+				Error:   reorderErr.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reorderErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, updatedTask)
+
+	c.JSON(http.StatusOK, interfaces.RetrieveSubtasksResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: reorderedSubtasks,
+	})
+}
+
+func DeleteSubtask(c *gin.Context) {
+	var reqParams deleteSubtaskParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	_, listId, retrieveListIdErr := retrieveListIdBySubtaskId(reqParams.Id)
+	if retrieveListIdErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListIdErr.Error(),
+		})
+		return
+	}
+
+	verifyErr := verifyUserWritePerms(listId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	deletedSubtask, updatedTask, deleteSubtaskErr := taskService.DeleteSubtask(db.Database, userId, reqParams.Id)
+	if deleteSubtaskErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   deleteSubtaskErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, updatedTask)
+
+	c.JSON(http.StatusOK, interfaces.DeleteSubtaskResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: deletedSubtask,
+	})
+}
+
+func RetrieveSubtasksByTaskId(c *gin.Context) {
+	var reqParams retrieveSubtasksByTaskIdParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	listId, retrieveListIdErr := taskService.RetrieveListIdByTaskId(reqParams.TaskId)
+	if retrieveListIdErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListIdErr.Error(),
+		})
+		return
+	}
+
+	verifyErr := verifyUserWritePerms(listId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	subtasks, retrieveSubtasksErr := taskService.RetrieveSubtasksByTaskId(reqParams.TaskId)
+	if retrieveSubtasksErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveSubtasksErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveSubtasksResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: subtasks,
+	})
+}
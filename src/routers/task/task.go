@@ -1,50 +1,82 @@
 package router
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/beebeeoii/do-gether/db"
 	"github.com/beebeeoii/do-gether/interfaces"
 	validator "github.com/beebeeoii/do-gether/routers/validator"
+	eventService "github.com/beebeeoii/do-gether/services/event"
 	listService "github.com/beebeeoii/do-gether/services/list"
+	recurrenceService "github.com/beebeeoii/do-gether/services/recurrence"
+	tagService "github.com/beebeeoii/do-gether/services/tag"
 	taskService "github.com/beebeeoii/do-gether/services/task"
+	userService "github.com/beebeeoii/do-gether/services/user"
 	"github.com/gin-gonic/gin"
 )
 
 type createTaskBody struct {
 	Owner        string   `json:"owner" validate:"min=1,max=20,required"`
 	Title        string   `json:"title" validate:"required"`
-	Tags         []string `json:"tags" validate:"required"`
+	Description  string   `json:"description" validate:"max=20000"`
+	Tags         []string `json:"tags" validate:"required,dive,min=1,max=60"`
 	ListId       string   `json:"listId" validate:"min=1,max=20,required"`
 	Priority     int      `json:"priority"`
 	Due          int      `json:"due" validate:"required"`
 	PlannedStart int      `json:"plannedStart" validate:"required"`
 	PlannedEnd   int      `json:"plannedEnd" validate:"required"`
+	Recurrence   string   `json:"recurrence"`
+	Assignees    []string `json:"assignees" validate:"unique,dive,min=1,max=20"`
 }
 
 type editTaskBody struct {
 	Id           string   `json:"id" validate:"min=1,max=20,required"`
 	ListId       string   `json:"listId" validate:"min=1,max=20,required"`
 	Title        string   `json:"title" validate:"required"`
-	Tags         []string `json:"tags" validate:"required"`
+	Description  *string  `json:"description" validate:"omitempty,max=20000"` // nil keeps the current description
+	Tags         []string `json:"tags" validate:"required,dive,min=1,max=60"`
 	Priority     int      `json:"priority"`
 	Due          int      `json:"due" validate:"required"`
 	PlannedStart int      `json:"plannedStart" validate:"required"`
 	PlannedEnd   int      `json:"plannedEnd" validate:"required"`
+	Recurrence   *string  `json:"recurrence"`                                    // nil keeps the current recurrence
+	Assignees    []string `json:"assignees" validate:"unique,dive,min=1,max=20"` // nil keeps the current assignees
 }
 
 type editTaskCompletedBody struct {
 	Id        string `json:"id" validate:"min=1,max=20,required"`
 	ListId    string `json:"listId" validate:"min=1,max=20,required"`
 	Completed bool   `json:"completed"`
+	Force     bool   `json:"force"`                      // complete even if blockers are still open
+	Timezone  string `json:"timezone" validate:"max=64"` // IANA name recurrences are expanded in, defaults to UTC
+}
+
+type editTaskAssigneesBody struct {
+	Id        string   `json:"id" validate:"min=1,max=20,required"`
+	ListId    string   `json:"listId" validate:"min=1,max=20,required"`
+	Assignees []string `json:"assignees" validate:"required,unique,dive,min=1,max=20"`
 }
 
 type deleteTaskParams struct {
 	Id string `form:"taskId" validate:"required,min=1,max=20"`
 }
 
+type restoreTaskBody struct {
+	Id string `json:"id" validate:"min=1,max=20,required"`
+}
+
+type archiveTaskBody struct {
+	Id       string `json:"id" validate:"min=1,max=20,required"`
+	ListId   string `json:"listId" validate:"min=1,max=20,required"`
+	Archived bool   `json:"archived"`
+}
+
 type retrieveTasksByListIdParams struct {
-	ListId string `form:"listId" validate:"required,min=1,max=20"`
+	ListId          string `form:"listId" validate:"required,min=1,max=20"`
+	IncludeArchived bool   `form:"includeArchived"`
 }
 
 type retrieveTagSuggestionParams struct {
@@ -54,7 +86,7 @@ type retrieveTagSuggestionParams struct {
 type reorderTaskBody struct {
 	Id           string `json:"id" validate:"min=1,max=20,required"`
 	ListId       string `json:"listId" validate:"min=1,max=20,required"`
-	NewListOrder int    `json:"newListOrder"`
+	NewListOrder int    `json:"newListOrder" validate:"min=0"`
 }
 
 type moveTaskBody struct {
@@ -63,10 +95,6 @@ type moveTaskBody struct {
 	NewListId      string `json:"newListId" validate:"min=1,max=20,required"`
 }
 
-const (
-	USER_ID_HEADER_KEY = "id"
-)
-
 func verifyUserWritePerms(listId string, userId string) error {
 	list, retrieveListErr := listService.RetrieveListById(listId)
 	if retrieveListErr != nil {
@@ -80,57 +108,47 @@ func verifyUserWritePerms(listId string, userId string) error {
 	return nil
 }
 
-func reorderTasksInList(taskId string, listId string, taskNewOrder int) ([]interfaces.BasicTaskReorderData, error) {
-	var updatedTasks []interfaces.BasicTaskReorderData
-	taskInitialOrder := -1
-
-	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(listId)
-	if retrieveTasksErr != nil {
-		return updatedTasks, retrieveTasksErr
-	}
-
-	for index, task := range tasks {
-		if task.Id == taskId {
-			taskInitialOrder = task.ListOrder
-		}
-
-		updatedTasks = append(updatedTasks, interfaces.BasicTaskReorderData{
-			Id:        task.Id,
-			ListOrder: index,
-		})
-	}
-
-	if taskInitialOrder == -1 {
-		return updatedTasks, fmt.Errorf("task not found in list")
-	}
-
-	updatedTasks[taskInitialOrder].ListOrder = taskNewOrder
-
-	if taskInitialOrder > taskNewOrder {
-		for i := taskNewOrder; i < taskInitialOrder; i++ {
-			updatedTasks[i].ListOrder += 1
-		}
+func verifyAssignees(listId string, assignees []string) error {
+	list, retrieveListErr := listService.RetrieveListById(listId)
+	if retrieveListErr != nil {
+		return retrieveListErr
 	}
 
-	if taskNewOrder > taskInitialOrder {
-		for i := taskInitialOrder + 1; i < taskNewOrder+1; i++ {
-			updatedTasks[i].ListOrder -= 1
+	for _, assigneeId := range assignees {
+		_, retrieveUserErr := userService.RetrieveUserById(assigneeId)
+		if retrieveUserErr != nil || !validator.HasListReadWritePermission(list, assigneeId) {
+			return fmt.Errorf("invalid assignee")
 		}
 	}
 
-	return updatedTasks, nil
+	return nil
 }
 
-func CreateTask(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+// respondWithCurrentTask rejects an edit based on an outdated version of a
+// task and sends back the current copy, so that the client can merge. status
+// is 412 if the If-Match header was already outdated, or 409 if another edit
+// got in between the check and the write.
+func respondWithCurrentTask(c *gin.Context, status int, taskId string) {
+	currentTask, retrieveTaskErr := taskService.RetrieveTaskById(taskId)
+	if retrieveTaskErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
 			Success: false,
-			Error:   authDataValidationErr.Error(),
+			Error:   retrieveTaskErr.Error(),
 		})
 		return
 	}
 
+	c.Header("ETag", validator.ETag(currentTask.Version))
+	c.JSON(status, interfaces.EditTaskResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: false,
+			Error:   db.ErrVersionConflict.Error(),
+		},
+		Data: currentTask,
+	})
+}
+
+func CreateTask(c *gin.Context) {
 	var requestBody createTaskBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -141,6 +159,7 @@ func CreateTask(c *gin.Context) {
 		})
 		return
 	}
+	requestBody.Tags = tagService.NormaliseAll(requestBody.Tags)
 
 	validationErr := validator.Validate.Struct(requestBody)
 	if validationErr != nil {
@@ -151,7 +170,7 @@ func CreateTask(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	if requestBody.Owner != userId {
 		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
@@ -170,18 +189,43 @@ func CreateTask(c *gin.Context) {
 		return
 	}
 
+	recurrence, recurrenceErr := recurrenceService.Normalise(requestBody.Recurrence)
+	if recurrenceErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   recurrenceErr.Error(),
+		})
+		return
+	}
+
+	if requestBody.Assignees == nil {
+		requestBody.Assignees = []string{}
+	}
+
+	verifyAssigneesErr := verifyAssignees(requestBody.ListId, requestBody.Assignees)
+	if verifyAssigneesErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyAssigneesErr.Error(),
+		})
+		return
+	}
+
 	taskCreationData := interfaces.TaskCreationData{
 		Owner:        requestBody.Owner,
 		Title:        requestBody.Title,
+		Description:  requestBody.Description,
 		Tags:         requestBody.Tags,
 		ListId:       requestBody.ListId,
 		Priority:     requestBody.Priority,
 		Due:          requestBody.Due,
 		PlannedStart: requestBody.PlannedStart,
 		PlannedEnd:   requestBody.PlannedEnd,
+		Recurrence:   recurrence,
+		Assignees:    requestBody.Assignees,
 	}
 
-	newTask, createTaskErr := taskService.CreateTask(taskCreationData)
+	newTask, createTaskErr := taskService.CreateTask(db.Database, userId, taskCreationData)
 	if createTaskErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -190,6 +234,9 @@ func CreateTask(c *gin.Context) {
 		return
 	}
 
+	eventService.PublishTask(interfaces.CHANGE_TASK_CREATED, userId, newTask)
+
+	c.Header("ETag", validator.ETag(newTask.Version))
 	c.JSON(http.StatusOK, interfaces.CreateTaskResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
@@ -200,15 +247,6 @@ func CreateTask(c *gin.Context) {
 }
 
 func EditTask(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var requestBody editTaskBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -219,6 +257,7 @@ func EditTask(c *gin.Context) {
 		})
 		return
 	}
+	requestBody.Tags = tagService.NormaliseAll(requestBody.Tags)
 
 	validationErr := validator.Validate.Struct(requestBody)
 	if validationErr != nil {
@@ -229,7 +268,16 @@ func EditTask(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	verifyErr := verifyUserWritePerms(requestBody.ListId, userId)
 	if verifyErr != nil {
@@ -240,7 +288,7 @@ func EditTask(c *gin.Context) {
 		return
 	}
 
-	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(requestBody.ListId)
+	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(requestBody.ListId, true)
 	if retrieveTasksErr != nil {
 		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
 			Success: false,
@@ -250,10 +298,24 @@ func EditTask(c *gin.Context) {
 	}
 
 	doesTaskExistInList := false
+	currentVersion := 0
 
 	for _, task := range tasks {
 		if task.Id == requestBody.Id {
 			doesTaskExistInList = true
+			currentVersion = task.Version
+
+			if requestBody.Description == nil {
+				requestBody.Description = &task.Description
+			}
+
+			if requestBody.Recurrence == nil {
+				requestBody.Recurrence = &task.Recurrence
+			}
+
+			if requestBody.Assignees == nil {
+				requestBody.Assignees = task.Assignees
+			}
 			break
 		}
 	}
@@ -266,18 +328,50 @@ func EditTask(c *gin.Context) {
 		return
 	}
 
+	if currentVersion != ifMatchVersion {
+		respondWithCurrentTask(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
+	recurrence, recurrenceErr := recurrenceService.Normalise(*requestBody.Recurrence)
+	if recurrenceErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   recurrenceErr.Error(),
+		})
+		return
+	}
+
+	verifyAssigneesErr := verifyAssignees(requestBody.ListId, requestBody.Assignees)
+	if verifyAssigneesErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyAssigneesErr.Error(),
+		})
+		return
+	}
+
 	taskEditionData := interfaces.TaskEditionData{
 		Id:           requestBody.Id,
 		Title:        requestBody.Title,
+		Description:  *requestBody.Description,
 		Tags:         requestBody.Tags,
 		Priority:     requestBody.Priority,
 		Due:          requestBody.Due,
 		PlannedStart: requestBody.PlannedStart,
 		PlannedEnd:   requestBody.PlannedEnd,
+		Recurrence:   recurrence,
+		Assignees:    requestBody.Assignees,
+		Version:      ifMatchVersion,
 	}
 
-	updatedTask, editTaskErr := taskService.EditTask(taskEditionData)
+	updatedTask, editTaskErr := taskService.EditTask(db.Database, userId, taskEditionData)
 	if editTaskErr != nil {
+		if errors.Is(editTaskErr, db.ErrVersionConflict) {
+			respondWithCurrentTask(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
 			Error:   editTaskErr.Error(),
@@ -285,6 +379,9 @@ func EditTask(c *gin.Context) {
 		return
 	}
 
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, updatedTask)
+
+	c.Header("ETag", validator.ETag(updatedTask.Version))
 	c.JSON(http.StatusOK, interfaces.EditTaskResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
@@ -295,15 +392,6 @@ func EditTask(c *gin.Context) {
 }
 
 func DeleteTask(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var reqParams deleteTaskParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -324,7 +412,7 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	listId, retrieveListIdErr := taskService.RetrieveListIdByTaskId(reqParams.Id)
 	if retrieveListIdErr != nil {
@@ -344,7 +432,7 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
-	deletedTask, deleteTaskErr := taskService.DeleteTask(reqParams.Id)
+	deletedTask, deleteTaskErr := taskService.DeleteTask(db.Database, userId, reqParams.Id)
 	if deleteTaskErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -353,6 +441,8 @@ func DeleteTask(c *gin.Context) {
 		return
 	}
 
+	eventService.PublishTask(interfaces.CHANGE_TASK_DELETED, userId, deletedTask)
+
 	c.JSON(http.StatusOK, interfaces.DeleteTaskResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
@@ -362,16 +452,158 @@ func DeleteTask(c *gin.Context) {
 	})
 }
 
-func RetrieveTasksByListId(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
+func ArchiveTask(c *gin.Context) {
+	var requestBody archiveTaskBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	verifyErr := verifyUserWritePerms(requestBody.ListId, userId)
+	if verifyErr != nil {
 		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
 			Success: false,
-			Error:   authDataValidationErr.Error(),
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	task, retrieveTaskErr := taskService.RetrieveTaskById(requestBody.Id)
+	if retrieveTaskErr != nil || task.ListId != requestBody.ListId {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("task does not exist in the list").Error(),
+		})
+		return
+	}
+
+	if task.Version != ifMatchVersion {
+		respondWithCurrentTask(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
+	updatedTask, archiveTaskErr := taskService.ArchiveTask(db.Database, userId, requestBody.Id, requestBody.Archived, ifMatchVersion)
+	if archiveTaskErr != nil {
+		if errors.Is(archiveTaskErr, db.ErrVersionConflict) {
+			respondWithCurrentTask(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   archiveTaskErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, updatedTask)
+
+	c.Header("ETag", validator.ETag(updatedTask.Version))
+	c.JSON(http.StatusOK, interfaces.ArchiveTaskResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedTask,
+	})
+}
+
+func RestoreTask(c *gin.Context) {
+	var requestBody restoreTaskBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	deletedTask, retrieveTaskErr := taskService.RetrieveDeletedTaskById(requestBody.Id)
+	if retrieveTaskErr != nil {
+		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveTaskErr.Error(),
 		})
 		return
 	}
 
+	if deletedTask.DeletedBy != userId {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("access denied").Error(),
+		})
+		return
+	}
+
+	verifyErr := verifyUserWritePerms(deletedTask.ListId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
+			Success: false,
+			Error:   verifyErr.Error(),
+		})
+		return
+	}
+
+	restoredTask, restoreTaskErr := taskService.RestoreTask(db.Database, userId, requestBody.Id)
+	if restoreTaskErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   restoreTaskErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishTask(interfaces.CHANGE_TASK_CREATED, userId, restoredTask)
+
+	c.Header("ETag", validator.ETag(restoredTask.Version))
+	c.JSON(http.StatusOK, interfaces.RestoreTaskResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: restoredTask,
+	})
+}
+
+func RetrieveTasksByListId(c *gin.Context) {
 	var reqParams retrieveTasksByListIdParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -392,7 +624,7 @@ func RetrieveTasksByListId(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	verifyErr := verifyUserWritePerms(reqParams.ListId, userId)
 	if verifyErr != nil {
@@ -403,7 +635,7 @@ func RetrieveTasksByListId(c *gin.Context) {
 		return
 	}
 
-	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(reqParams.ListId)
+	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(reqParams.ListId, reqParams.IncludeArchived)
 	if retrieveTasksErr != nil {
 		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
 			Success: false,
@@ -422,15 +654,6 @@ func RetrieveTasksByListId(c *gin.Context) {
 }
 
 func RetrieveTagSuggestion(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var reqParams retrieveTagSuggestionParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -451,7 +674,7 @@ func RetrieveTagSuggestion(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	verifyErr := verifyUserWritePerms(reqParams.ListId, userId)
 	if verifyErr != nil {
@@ -462,7 +685,7 @@ func RetrieveTagSuggestion(c *gin.Context) {
 		return
 	}
 
-	tags, retrieveTagsErr := taskService.RetrieveTagsByListId(reqParams.ListId)
+	tags, retrieveTagsErr := taskService.RetrieveTagsWithUsageByListId(reqParams.ListId)
 	if retrieveTagsErr != nil {
 		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
 			Success: false,
@@ -476,20 +699,11 @@ func RetrieveTagSuggestion(c *gin.Context) {
 			Success: true,
 			Error:   "",
 		},
-		Data: tags,
+		Data: tagService.BuildTree(tags),
 	})
 }
 
 func ReorderTasks(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var requestBody reorderTaskBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -510,7 +724,7 @@ func ReorderTasks(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	verifyErr := verifyUserWritePerms(requestBody.ListId, userId)
 	if verifyErr != nil {
@@ -521,16 +735,7 @@ func ReorderTasks(c *gin.Context) {
 		return
 	}
 
-	reorderTaskData, reorderErr := reorderTasksInList(requestBody.Id, requestBody.ListId, requestBody.NewListOrder)
-	if reorderErr != nil {
-		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
-			Success: false,
-			Error:   reorderErr.Error(),
-		})
-		return
-	}
-
-	tasks, reorderTasksErr := taskService.ReorderTasksInList(reorderTaskData)
+	tasks, reorderTasksErr := taskService.ReorderTask(db.Database, userId, requestBody.Id, requestBody.ListId, requestBody.NewListOrder)
 	if reorderTasksErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -539,6 +744,8 @@ func ReorderTasks(c *gin.Context) {
 		return
 	}
 
+	eventService.PublishTasks(interfaces.CHANGE_TASKS_REORDERED, userId, requestBody.ListId, tasks)
+
 	c.JSON(http.StatusOK, interfaces.RetrieveTasksResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
@@ -549,15 +756,6 @@ func ReorderTasks(c *gin.Context) {
 }
 
 func EditTaskCompleted(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var requestBody editTaskCompletedBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -578,7 +776,25 @@ func EditTaskCompleted(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	location, loadLocationErr := time.LoadLocation(requestBody.Timezone)
+	if loadLocationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("invalid timezone").Error(),
+		})
+		return
+	}
+
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	verifyErr := verifyUserWritePerms(requestBody.ListId, userId)
 	if verifyErr != nil {
@@ -589,7 +805,7 @@ func EditTaskCompleted(c *gin.Context) {
 		return
 	}
 
-	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(requestBody.ListId)
+	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(requestBody.ListId, true)
 	if retrieveTasksErr != nil {
 		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
 			Success: false,
@@ -599,10 +815,12 @@ func EditTaskCompleted(c *gin.Context) {
 	}
 
 	doesTaskExistInList := false
+	currentVersion := 0
 
 	for _, task := range tasks {
 		if task.Id == requestBody.Id {
 			doesTaskExistInList = true
+			currentVersion = task.Version
 			break
 		}
 	}
@@ -615,13 +833,33 @@ func EditTaskCompleted(c *gin.Context) {
 		return
 	}
 
+	if currentVersion != ifMatchVersion {
+		respondWithCurrentTask(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
 	taskEditCompletedData := interfaces.TaskEditCompletedData{
 		Id:        requestBody.Id,
 		Completed: requestBody.Completed,
+		Force:     requestBody.Force,
+		Version:   ifMatchVersion,
 	}
 
-	updatedTask, editTaskErr := taskService.EditTaskCompleted(taskEditCompletedData)
+	updatedTask, nextOccurrence, editTaskErr := taskService.EditTaskCompleted(db.Database, userId, taskEditCompletedData, location)
 	if editTaskErr != nil {
+		if errors.Is(editTaskErr, db.ErrVersionConflict) {
+			respondWithCurrentTask(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
+		if errors.Is(editTaskErr, taskService.ErrTaskBlocked) {
+			c.JSON(http.StatusConflict, interfaces.BaseResponse{
+				Success: false,
+				Error:   editTaskErr.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
 			Error:   editTaskErr.Error(),
@@ -629,25 +867,23 @@ func EditTaskCompleted(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, interfaces.EditTaskResponse{
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, updatedTask)
+	if nextOccurrence != nil {
+		eventService.PublishTask(interfaces.CHANGE_TASK_CREATED, userId, *nextOccurrence)
+	}
+
+	c.Header("ETag", validator.ETag(updatedTask.Version))
+	c.JSON(http.StatusOK, interfaces.EditTaskCompletedResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
 			Error:   "",
 		},
-		Data: updatedTask,
+		Data:           updatedTask,
+		NextOccurrence: nextOccurrence,
 	})
 }
 
 func MoveTask(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var requestBody moveTaskBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -676,7 +912,16 @@ func MoveTask(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	verifyOldListErr := verifyUserWritePerms(requestBody.OriginalListId, userId)
 	if verifyOldListErr != nil {
@@ -696,7 +941,7 @@ func MoveTask(c *gin.Context) {
 		return
 	}
 
-	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(requestBody.OriginalListId)
+	tasks, retrieveTasksErr := taskService.RetrieveTasksByListId(requestBody.OriginalListId, false)
 	if retrieveTasksErr != nil {
 		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
 			Success: false,
@@ -706,10 +951,12 @@ func MoveTask(c *gin.Context) {
 	}
 
 	doesTaskExistInList := false
+	currentVersion := 0
 
 	for _, task := range tasks {
 		if task.Id == requestBody.Id {
 			doesTaskExistInList = true
+			currentVersion = task.Version
 			break
 		}
 	}
@@ -722,60 +969,169 @@ func MoveTask(c *gin.Context) {
 		return
 	}
 
-	nTasksInOriginalList, retrieveNTasksErr := listService.RetrieveNumberTasksInList(requestBody.OriginalListId)
-	if retrieveNTasksErr != nil {
-		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
+	if currentVersion != ifMatchVersion {
+		respondWithCurrentTask(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
+	updatedTask, moveTaskErr := taskService.MoveTask(db.Database, userId, interfaces.MoveTaskData{
+		Id:        requestBody.Id,
+		NewListId: requestBody.NewListId,
+		Version:   ifMatchVersion,
+	})
+	if moveTaskErr != nil {
+		if errors.Is(moveTaskErr, db.ErrVersionConflict) {
+			respondWithCurrentTask(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   moveTaskErr.Error(),
+		})
+		return
+	}
+
+	eventService.PublishTask(interfaces.CHANGE_TASK_MOVED, userId, updatedTask, requestBody.OriginalListId)
+
+	c.Header("ETag", validator.ETag(updatedTask.Version))
+	c.JSON(http.StatusOK, interfaces.MoveTaskResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedTask,
+	})
+}
+
+func EditTaskAssignees(c *gin.Context) {
+	var requestBody editTaskAssigneesBody
+
+	reqBodyErr := c.BindJSON(&requestBody)
+	if reqBodyErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqBodyErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(requestBody)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	ifMatchVersion, ifMatchErr := validator.ParseIfMatch(c.Request.Header)
+	if ifMatchErr != nil {
+		c.JSON(http.StatusPreconditionRequired, interfaces.BaseResponse{
+			Success: false,
+			Error:   ifMatchErr.Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	verifyErr := verifyUserWritePerms(requestBody.ListId, userId)
+	if verifyErr != nil {
+		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
 			Success: false,
-			Error:   retrieveNTasksErr.Error(),
+			Error:   verifyErr.Error(),
 		})
 		return
 	}
 
-	nTasksInNewList, retrieveNTasksErr := listService.RetrieveNumberTasksInList(requestBody.NewListId)
-	if retrieveNTasksErr != nil {
+	currentTask, retrieveTaskErr := taskService.RetrieveTaskById(requestBody.Id)
+	if retrieveTaskErr != nil || currentTask.ListId != requestBody.ListId {
 		c.JSON(http.StatusNotFound, interfaces.BaseResponse{
 			Success: false,
-			Error:   retrieveNTasksErr.Error(),
+			Error:   fmt.Errorf("task does not exist in the list").Error(),
 		})
 		return
 	}
 
-	reorderTaskData, reorderErr := reorderTasksInList(requestBody.Id, requestBody.OriginalListId, nTasksInOriginalList-1)
-	if reorderErr != nil {
-		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+	if currentTask.Version != ifMatchVersion {
+		respondWithCurrentTask(c, http.StatusPreconditionFailed, requestBody.Id)
+		return
+	}
+
+	verifyAssigneesErr := verifyAssignees(requestBody.ListId, requestBody.Assignees)
+	if verifyAssigneesErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
 			Success: false,
-			Error:   reorderErr.Error(),
+			Error:   verifyAssigneesErr.Error(),
 		})
 		return
 	}
 
-	_, reorderTasksErr := taskService.ReorderTasksInList(reorderTaskData)
-	if reorderTasksErr != nil {
+	updatedTask, editTaskErr := taskService.EditTaskAssignees(db.Database, userId, interfaces.TaskEditAssigneesData{
+		Id:        requestBody.Id,
+		Assignees: requestBody.Assignees,
+		Version:   ifMatchVersion,
+	})
+	if editTaskErr != nil {
+		if errors.Is(editTaskErr, db.ErrVersionConflict) {
+			respondWithCurrentTask(c, http.StatusConflict, requestBody.Id)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
-			Error:   reorderTasksErr.Error(),
+			Error:   editTaskErr.Error(),
 		})
 		return
 	}
 
-	updatedTask, moveTaskErr := taskService.MoveTask(interfaces.MoveTaskData{
-		Id:           requestBody.Id,
-		NewListId:    requestBody.NewListId,
-		NewListOrder: nTasksInNewList,
+	eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, userId, updatedTask)
+
+	c.Header("ETag", validator.ETag(updatedTask.Version))
+	c.JSON(http.StatusOK, interfaces.EditTaskResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: updatedTask,
 	})
-	if moveTaskErr != nil {
+}
+
+func RetrieveAssignedTasks(c *gin.Context) {
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	tasks, retrieveTasksErr := taskService.RetrieveTasksByAssignee(userId)
+	if retrieveTasksErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
-			Error:   moveTaskErr.Error(),
+			Error:   retrieveTasksErr.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, interfaces.MoveTaskResponse{
+	// Assignees who have since lost access to a list keep their assignment
+	// until it is edited, so only return tasks from lists still readable.
+	readableLists := make(map[string]bool)
+	assignedTasks := []interfaces.Task{}
+
+	for _, task := range tasks {
+		readable, checked := readableLists[task.ListId]
+		if !checked {
+			readable = verifyUserWritePerms(task.ListId, userId) == nil
+			readableLists[task.ListId] = readable
+		}
+
+		if readable {
+			assignedTasks = append(assignedTasks, task)
+		}
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveTasksResponse{
 		BaseResponse: interfaces.BaseResponse{
 			Success: true,
 			Error:   "",
 		},
-		Data: updatedTask,
+		Data: assignedTasks,
 	})
 }
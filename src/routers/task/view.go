@@ -0,0 +1,110 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	TASK_VIEW_TODAY    = "today"
+	TASK_VIEW_OVERDUE  = "overdue"
+	TASK_VIEW_UPCOMING = "upcoming"
+	TASK_VIEW_PLANNED  = "planned"
+
+	UPCOMING_DAYS = 7
+)
+
+type retrieveTaskViewParams struct {
+	View     string `form:"view" validate:"required,oneof=today overdue upcoming planned"`
+	Timezone string `form:"timezone" validate:"max=64"` // IANA name, defaults to UTC
+}
+
+// RetrieveTaskView aggregates open tasks across every list the user owns or
+// is a member of. Days are cut at midnight in the requested timezone.
+func RetrieveTaskView(c *gin.Context) {
+	var reqParams retrieveTaskViewParams
+
+	reqParamsErr := c.BindQuery(&reqParams)
+	if reqParamsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   reqParamsErr.Error(),
+		})
+		return
+	}
+
+	validationErr := validator.Validate.Struct(reqParams)
+	if validationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   validationErr.Error(),
+		})
+		return
+	}
+
+	location, loadLocationErr := time.LoadLocation(reqParams.Timezone)
+	if loadLocationErr != nil {
+		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
+			Success: false,
+			Error:   fmt.Errorf("invalid timezone").Error(),
+		})
+		return
+	}
+
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	lists, retrieveListsErr := listService.RetrieveListsByUserId(userId, userId, false)
+	if retrieveListsErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveListsErr.Error(),
+		})
+		return
+	}
+
+	listIds := []string{}
+	for _, list := range lists {
+		listIds = append(listIds, list.Id)
+	}
+
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	startOfToday := int(today.Unix())
+	startOfTomorrow := int(today.AddDate(0, 0, 1).Unix())
+
+	var tasks []interfaces.Task
+	var retrieveTasksErr error
+
+	switch reqParams.View {
+	case TASK_VIEW_TODAY:
+		tasks, retrieveTasksErr = taskService.RetrieveOpenTasksDueBetween(listIds, startOfToday, startOfTomorrow)
+	case TASK_VIEW_OVERDUE:
+		tasks, retrieveTasksErr = taskService.RetrieveOpenTasksDueBetween(listIds, 0, startOfToday)
+	case TASK_VIEW_UPCOMING:
+		tasks, retrieveTasksErr = taskService.RetrieveOpenTasksDueBetween(listIds, startOfTomorrow, int(today.AddDate(0, 0, 1+UPCOMING_DAYS).Unix()))
+	case TASK_VIEW_PLANNED:
+		tasks, retrieveTasksErr = taskService.RetrieveOpenTasksPlannedBetween(listIds, startOfToday, startOfTomorrow)
+	}
+	if retrieveTasksErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveTasksErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveTasksResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: tasks,
+	})
+}
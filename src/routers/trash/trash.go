@@ -0,0 +1,31 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+	validator "github.com/beebeeoii/do-gether/routers/validator"
+	trashService "github.com/beebeeoii/do-gether/services/trash"
+	"github.com/gin-gonic/gin"
+)
+
+func RetrieveTrash(c *gin.Context) {
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
+
+	trash, retrieveTrashErr := trashService.RetrieveTrash(userId)
+	if retrieveTrashErr != nil {
+		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
+			Success: false,
+			Error:   retrieveTrashErr.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, interfaces.RetrieveTrashResponse{
+		BaseResponse: interfaces.BaseResponse{
+			Success: true,
+			Error:   "",
+		},
+		Data: trash,
+	})
+}
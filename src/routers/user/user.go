@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/beebeeoii/do-gether/db"
 	"github.com/beebeeoii/do-gether/interfaces"
 	validator "github.com/beebeeoii/do-gether/routers/validator"
 	authService "github.com/beebeeoii/do-gether/services/auth"
@@ -38,7 +39,6 @@ type removeFriendParams struct {
 }
 
 const (
-	USER_ID_HEADER_KEY = "id"
 	USER_ID_PARAM_KEY  = "id"
 	USERNAME_PARAM_KEY = "username"
 )
@@ -73,7 +73,7 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	newUser, createUserErr := userService.CreateUser(requestBody.Username, hashedPassword)
+	newUser, createUserErr := userService.CreateUser(db.Database, requestBody.Username, hashedPassword)
 	if createUserErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -92,18 +92,9 @@ func Register(c *gin.Context) {
 }
 
 func RetrieveUserById(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	userId := c.Param(USER_ID_PARAM_KEY)
 
-	if userId != c.GetHeader(USER_ID_HEADER_KEY) {
+	if userId != c.GetString(validator.USER_ID_CONTEXT_KEY) {
 		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
 			Success: false,
 			Error:   fmt.Errorf("access denied").Error(),
@@ -136,16 +127,7 @@ func RetrieveUserById(c *gin.Context) {
 }
 
 func RetrieveAllUserFriends(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	user, retrieveErr := userService.RetrieveUserById(userId)
 	if retrieveErr != nil {
@@ -219,15 +201,6 @@ func RetrieveAllUserFriends(c *gin.Context) {
 }
 
 func FindUserByUsername(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var reqParams findUserByUsernameParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -257,7 +230,7 @@ func FindUserByUsername(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	if !utils.Contains(user.Friends, userId) {
 		user = interfaces.User{
@@ -279,15 +252,6 @@ func FindUserByUsername(c *gin.Context) {
 }
 
 func SendFriendReq(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var requestBody sendFriendReqBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -309,7 +273,7 @@ func SendFriendReq(c *gin.Context) {
 	}
 
 	recipientId := requestBody.Id
-	senderId := c.GetHeader(USER_ID_HEADER_KEY)
+	senderId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
 	if recipientId == senderId {
 		c.JSON(http.StatusBadRequest, interfaces.BaseResponse{
@@ -319,7 +283,7 @@ func SendFriendReq(c *gin.Context) {
 		return
 	}
 
-	sendReqErr := userService.SendFriendRequest(senderId, recipientId)
+	sendReqErr := userService.SendFriendRequest(db.Database, senderId, recipientId)
 	if sendReqErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -335,15 +299,6 @@ func SendFriendReq(c *gin.Context) {
 }
 
 func AcceptFriendReq(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var requestBody acceptFriendReqBody
 
 	reqBodyErr := c.BindJSON(&requestBody)
@@ -365,9 +320,9 @@ func AcceptFriendReq(c *gin.Context) {
 	}
 
 	senderId := requestBody.Id
-	recipientId := c.GetHeader(USER_ID_HEADER_KEY)
+	recipientId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
-	acceptReqErr := userService.AcceptFriendRequest(senderId, recipientId)
+	acceptReqErr := userService.AcceptFriendRequest(db.Database, senderId, recipientId)
 	if acceptReqErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -383,15 +338,6 @@ func AcceptFriendReq(c *gin.Context) {
 }
 
 func RemoveFriendRequest(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var reqParams removeFriendRequestParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -412,9 +358,9 @@ func RemoveFriendRequest(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader(USER_ID_HEADER_KEY)
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
-	removeFriendErr := userService.RemoveFriendRequest(userId, reqParams.Id)
+	removeFriendErr := userService.RemoveFriendRequest(db.Database, userId, reqParams.Id)
 	if removeFriendErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
@@ -430,15 +376,6 @@ func RemoveFriendRequest(c *gin.Context) {
 }
 
 func RemoveFriend(c *gin.Context) {
-	authDataValidationErr := validator.ValidateAuthDataFromHeader(c.Request.Header)
-	if authDataValidationErr != nil {
-		c.JSON(http.StatusUnauthorized, interfaces.BaseResponse{
-			Success: false,
-			Error:   authDataValidationErr.Error(),
-		})
-		return
-	}
-
 	var reqParams removeFriendParams
 
 	reqParamsErr := c.BindQuery(&reqParams)
@@ -459,9 +396,9 @@ func RemoveFriend(c *gin.Context) {
 		return
 	}
 
-	userId := c.GetHeader("id")
+	userId := c.GetString(validator.USER_ID_CONTEXT_KEY)
 
-	removeFriendErr := userService.RemoveFriend(userId, reqParams.Id)
+	removeFriendErr := userService.RemoveFriend(db.Database, userId, reqParams.Id)
 	if removeFriendErr != nil {
 		c.JSON(http.StatusInternalServerError, interfaces.BaseResponse{
 			Success: false,
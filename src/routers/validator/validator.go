@@ -1,32 +1,162 @@
 package router
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/beebeeoii/do-gether/db"
 	"github.com/beebeeoii/do-gether/interfaces"
 	authService "github.com/beebeeoii/do-gether/services/auth"
 	utils "github.com/beebeeoii/do-gether/services/utils"
+	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
+const (
+	// Context keys of the user a request was authenticated as, and of either
+	// the session their access token belongs to or the scopes their personal
+	// access token is limited to.
+	USER_ID_CONTEXT_KEY    = "userId"
+	SESSION_ID_CONTEXT_KEY = "sessionId"
+	SCOPES_CONTEXT_KEY     = "scopes"
+
+	// Query parameter carrying the access token of requests that cannot set
+	// the Authorization header, such as those of a browser EventSource.
+	ACCESS_TOKEN_QUERY_PARAM = "accessToken"
+)
+
 var Validate *validator.Validate
 
 func Init() {
 	Validate = validator.New()
 }
 
-func ValidateAuthDataFromHeader(header http.Header) error {
-	authData, authDataErr := authService.ExtractAuthData(header)
-	if authDataErr != nil {
-		return authDataErr
+// Authenticate checks the access token of an active session, or the personal
+// access token, that a request carries and puts the user it was issued to on
+// the context under USER_ID_CONTEXT_KEY. That is the only identity handlers
+// should trust. On failure, the status to reject the request with is
+// returned.
+func Authenticate(c *gin.Context) (int, error) {
+	token, extractTokenErr := authService.ExtractBearerToken(c.Request.Header)
+	if extractTokenErr != nil {
+		return http.StatusUnauthorized, extractTokenErr
 	}
 
-	_, validationErr := authService.ValidateAuthData(authData.Token, authData.UserId)
+	return authenticateToken(c, token)
+}
+
+// AuthenticateWithQueryToken is Authenticate for requests that may carry
+// the access token of a session in ACCESS_TOKEN_QUERY_PARAM instead. Query
+// strings end up in logs and browser history, so only access tokens, which
+// expire within minutes, are accepted there. Personal access tokens must
+// still be sent in the Authorization header.
+func AuthenticateWithQueryToken(c *gin.Context) (int, error) {
+	token := c.Query(ACCESS_TOKEN_QUERY_PARAM)
+	if token == "" || c.GetHeader("Authorization") != "" {
+		return Authenticate(c)
+	}
+
+	if authService.IsPersonalAccessToken(token) {
+		return http.StatusUnauthorized, fmt.Errorf("personal access tokens must be sent in the Authorization header")
+	}
+
+	return authenticateToken(c, token)
+}
+
+func authenticateToken(c *gin.Context, token string) (int, error) {
+	if authService.IsPersonalAccessToken(token) {
+		userId, scopes, validationErr := authService.ValidatePersonalAccessToken(db.Database, token)
+		if validationErr != nil {
+			if errors.Is(validationErr, authService.ErrInvalidPersonalAccessToken) {
+				return http.StatusUnauthorized, validationErr
+			}
+
+			return http.StatusInternalServerError, validationErr
+		}
+
+		c.Set(USER_ID_CONTEXT_KEY, userId)
+		c.Set(SCOPES_CONTEXT_KEY, scopes)
+
+		return http.StatusOK, nil
+	}
+
+	userId, sessionId, validationErr := authService.ValidateAccessToken(token)
 	if validationErr != nil {
-		return validationErr
+		return http.StatusUnauthorized, validationErr
+	}
+
+	verifySessionErr := authService.VerifySession(db.Database, sessionId, userId, c.ClientIP())
+	if verifySessionErr != nil {
+		if errors.Is(verifySessionErr, authService.ErrSessionRevoked) {
+			return http.StatusUnauthorized, verifySessionErr
+		}
+
+		return http.StatusInternalServerError, verifySessionErr
+	}
+
+	c.Set(USER_ID_CONTEXT_KEY, userId)
+	c.Set(SESSION_ID_CONTEXT_KEY, sessionId)
+
+	return http.StatusOK, nil
+}
+
+func AuthMiddleware() gin.HandlerFunc {
+	return authMiddleware(Authenticate)
+}
+
+// QueryTokenAuthMiddleware authenticates requests with
+// AuthenticateWithQueryToken, for routes browsers open without being able to
+// set headers.
+func QueryTokenAuthMiddleware() gin.HandlerFunc {
+	return authMiddleware(AuthenticateWithQueryToken)
+}
+
+func authMiddleware(authenticate func(c *gin.Context) (int, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, authErr := authenticate(c)
+		if authErr != nil {
+			c.AbortWithStatusJSON(status, interfaces.BaseResponse{
+				Success: false,
+				Error:   authErr.Error(),
+			})
+			return
+		}
+
+		c.Next()
 	}
+}
+
+// RequireScope limits personal access tokens to the routes their scopes
+// allow. GET requests need readScope or writeScope, and all other requests
+// need writeScope. Empty scopes turn personal access tokens away, while
+// access tokens of sessions may use every route.
+func RequireScope(readScope string, writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, isPersonalAccessToken := c.Get(SCOPES_CONTEXT_KEY)
+		if !isPersonalAccessToken {
+			c.Next()
+			return
+		}
+
+		tokenScopes := scopes.([]string)
+		isAllowed := writeScope != "" && utils.Contains(tokenScopes, writeScope)
+		if c.Request.Method == http.MethodGet && readScope != "" && utils.Contains(tokenScopes, readScope) {
+			isAllowed = true
+		}
 
-	return nil
+		if !isAllowed {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, interfaces.BaseResponse{
+				Success: false,
+				Error:   fmt.Errorf("personal access token lacks the scope for this route").Error(),
+			})
+			return
+		}
+
+		c.Next()
+	}
 }
 
 func HasListReadWritePermission(list interfaces.List, userId string) bool {
@@ -40,3 +170,24 @@ func HasListReadWritePermission(list interfaces.List, userId string) bool {
 func HasListEditPermission(list interfaces.List, userId string) bool {
 	return list.Owner == userId
 }
+
+// ETag formats the version of a task or list as an entity tag.
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ParseIfMatch returns the version named by the If-Match header, which edits
+// of tasks and lists must carry.
+func ParseIfMatch(header http.Header) (int, error) {
+	ifMatch := header.Get("If-Match")
+	if ifMatch == "" {
+		return 0, fmt.Errorf("If-Match header with the ETag of the edited version is required")
+	}
+
+	version, parseErr := strconv.Atoi(strings.Trim(ifMatch, "\""))
+	if parseErr != nil || version < 1 {
+		return 0, fmt.Errorf("invalid If-Match header %s", ifMatch)
+	}
+
+	return version, nil
+}
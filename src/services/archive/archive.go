@@ -0,0 +1,49 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	eventService "github.com/beebeeoii/do-gether/services/event"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+)
+
+const (
+	ARCHIVE_INTERVAL = time.Hour
+)
+
+// Init starts the background job that auto-archives completed tasks in lists
+// that opted in.
+func Init() {
+	go func() {
+		for {
+			archiveErr := archiveStaleCompletedTasks()
+			if archiveErr != nil {
+				log.Println(archiveErr)
+			}
+
+			time.Sleep(ARCHIVE_INTERVAL)
+		}
+	}()
+}
+
+// archiveStaleCompletedTasks archives the tasks due for it and lets the
+// subscribers of their lists know once the archiving is committed.
+func archiveStaleCompletedTasks() error {
+	return db.Transact(db.Database, func(tx db.Executor) error {
+		archivedTasks, archiveErr := taskService.ArchiveStaleCompletedTasks(tx)
+		if archiveErr != nil {
+			return archiveErr
+		}
+
+		return db.AfterCommit(tx, func() error {
+			for _, task := range archivedTasks {
+				eventService.PublishTask(interfaces.CHANGE_TASK_UPDATED, interfaces.TASK_EVENT_SYSTEM_ACTOR, task)
+			}
+
+			return nil
+		})
+	})
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"io"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+	"github.com/beebeeoii/do-gether/storage"
+)
+
+func CreateAttachment(ex db.Executor, attachment interfaces.AttachmentCreationData, content io.Reader) (interfaces.Attachment, error) {
+	sqlCommand := "INSERT INTO attachments (id, \"taskId\", uploader, filename, \"mimeType\", size, \"createdAt\") VALUES ($1, $2, $3, $4, $5, $6, $7);"
+
+	newAttachment := interfaces.Attachment{
+		Id:        utils.GenerateUid(),
+		TaskId:    attachment.TaskId,
+		Uploader:  attachment.Uploader,
+		Filename:  attachment.Filename,
+		MimeType:  attachment.MimeType,
+		Size:      attachment.Size,
+		CreatedAt: int(time.Now().Unix()),
+	}
+
+	putErr := storage.Storage.Put(newAttachment.Id, content, newAttachment.Size, newAttachment.MimeType)
+	if putErr != nil {
+		return newAttachment, putErr
+	}
+
+	_, execErr := ex.Exec(
+		sqlCommand,
+		newAttachment.Id,
+		newAttachment.TaskId,
+		newAttachment.Uploader,
+		newAttachment.Filename,
+		newAttachment.MimeType,
+		newAttachment.Size,
+		newAttachment.CreatedAt,
+	)
+	if execErr != nil {
+		storage.Storage.Delete(newAttachment.Id)
+		return newAttachment, execErr
+	}
+
+	return newAttachment, nil
+}
+
+func RetrieveAttachmentContent(attachmentId string) (io.ReadCloser, error) {
+	return storage.Storage.Get(attachmentId)
+}
+
+func DeleteAttachment(ex db.Executor, attachmentId string) (interfaces.Attachment, error) {
+	var deletedAttachment interfaces.Attachment
+	sqlCommand := "DELETE FROM attachments WHERE id = $1 RETURNING id, \"taskId\", uploader, filename, \"mimeType\", size, \"createdAt\";"
+
+	queryErr := ex.QueryRow(
+		sqlCommand,
+		attachmentId,
+	).Scan(
+		&deletedAttachment.Id,
+		&deletedAttachment.TaskId,
+		&deletedAttachment.Uploader,
+		&deletedAttachment.Filename,
+		&deletedAttachment.MimeType,
+		&deletedAttachment.Size,
+		&deletedAttachment.CreatedAt,
+	)
+	if queryErr != nil {
+		return deletedAttachment, queryErr
+	}
+
+	return deletedAttachment, db.AfterCommit(ex, func() error {
+		return storage.Storage.Delete(deletedAttachment.Id)
+	})
+}
+
+func DeleteAttachmentsFromTask(ex db.Executor, taskId string) error {
+	sqlCommand := "DELETE FROM attachments WHERE \"taskId\" = $1 RETURNING id;"
+
+	return deleteAttachments(ex, sqlCommand, taskId)
+}
+
+func DeleteAttachmentsFromList(ex db.Executor, listId string) error {
+	sqlCommand := "DELETE FROM attachments WHERE \"taskId\" IN (SELECT id FROM tasks WHERE \"listId\" = $1) RETURNING id;"
+
+	return deleteAttachments(ex, sqlCommand, listId)
+}
+
+// deleteAttachments removes the rows, and the stored files only once that is
+// committed, so a failure halfway leaves orphaned files rather than
+// attachments pointing at nothing.
+func deleteAttachments(ex db.Executor, sqlCommand string, args ...interface{}) error {
+	var attachmentIds []string
+
+	rows, queryErr := ex.Query(sqlCommand, args...)
+	if queryErr != nil {
+		return queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attachmentId string
+		scanErr := rows.Scan(&attachmentId)
+		if scanErr != nil {
+			return scanErr
+		}
+
+		attachmentIds = append(attachmentIds, attachmentId)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return rowsErr
+	}
+
+	return db.AfterCommit(ex, func() error {
+		for _, attachmentId := range attachmentIds {
+			deleteErr := storage.Storage.Delete(attachmentId)
+			if deleteErr != nil {
+				return deleteErr
+			}
+		}
+
+		return nil
+	})
+}
+
+func RetrieveAttachmentById(attachmentId string) (interfaces.Attachment, error) {
+	var attachment interfaces.Attachment
+	sqlCommand := "SELECT id, \"taskId\", uploader, filename, \"mimeType\", size, \"createdAt\" FROM attachments WHERE id = $1"
+
+	queryErr := db.Database.QueryRow(sqlCommand, attachmentId).Scan(
+		&attachment.Id,
+		&attachment.TaskId,
+		&attachment.Uploader,
+		&attachment.Filename,
+		&attachment.MimeType,
+		&attachment.Size,
+		&attachment.CreatedAt,
+	)
+
+	return attachment, queryErr
+}
+
+func RetrieveAttachmentsByTaskId(taskId string) ([]interfaces.Attachment, error) {
+	var attachments []interfaces.Attachment
+	sqlCommand := "SELECT id, \"taskId\", uploader, filename, \"mimeType\", size, \"createdAt\" FROM attachments WHERE \"taskId\" = $1 ORDER BY \"createdAt\" ASC"
+
+	rows, queryErr := db.Database.Query(sqlCommand, taskId)
+	if queryErr != nil {
+		return attachments, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		attachment := interfaces.Attachment{}
+		scanErr := rows.Scan(
+			&attachment.Id,
+			&attachment.TaskId,
+			&attachment.Uploader,
+			&attachment.Filename,
+			&attachment.MimeType,
+			&attachment.Size,
+			&attachment.CreatedAt,
+		)
+		if scanErr != nil {
+			return attachments, scanErr
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return attachments, rowsErr
+	}
+
+	return attachments, nil
+}
@@ -5,13 +5,24 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
-	"github.com/beebeeoii/do-gether/interfaces"
 	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const PASSWORD_HASH_COST = 10
+const (
+	PASSWORD_HASH_COST = 10
+
+	JWT_ISSUER            = "do-gether"
+	ACCESS_TOKEN_LIFETIME = 15 * time.Minute
+)
+
+type accessTokenClaims struct {
+	UserId    string `json:"id"`
+	SessionId string `json:"sid"`
+	jwt.StandardClaims
+}
 
 func HashPassword(password string) (string, error) {
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(generateFinalPassword(password)), PASSWORD_HASH_COST)
@@ -28,20 +39,36 @@ func generateFinalPassword(password string) string {
 	return fmt.Sprintf("%s%s", password, PASSWORD_SECRET)
 }
 
-func GenerateJwt(userId string) (string, error) {
+// GenerateJwt signs a short-lived access token for a session of a user, and
+// returns it along with the time it expires at.
+func GenerateJwt(userId string, sessionId string) (string, int, error) {
 	JWT_SECRET := os.Getenv("JWT_SECRET")
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id": userId,
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(ACCESS_TOKEN_LIFETIME).Unix()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims{
+		UserId:    userId,
+		SessionId: sessionId,
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    JWT_ISSUER,
+			IssuedAt:  issuedAt.Unix(),
+			ExpiresAt: expiresAt,
+		},
 	})
 
-	return token.SignedString([]byte(JWT_SECRET))
+	signedToken, signErr := token.SignedString([]byte(JWT_SECRET))
+
+	return signedToken, int(expiresAt), signErr
 }
 
-func ValidateAuthData(jwtToken string, userId string) (bool, error) {
+// ValidateAccessToken verifies an access token and returns the ids of the user
+// and session it was issued to. Whether the session is still active is up to
+// VerifySession.
+func ValidateAccessToken(jwtToken string) (string, string, error) {
 	JWT_SECRET := os.Getenv("JWT_SECRET")
 
-	token, tokenErr := jwt.Parse(jwtToken, func(token *jwt.Token) (interface{}, error) {
+	token, tokenErr := jwt.ParseWithClaims(jwtToken, &accessTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -50,27 +77,39 @@ func ValidateAuthData(jwtToken string, userId string) (bool, error) {
 	})
 
 	if tokenErr != nil {
-		return false, tokenErr
+		return "", "", tokenErr
+	}
+
+	claims, ok := token.Claims.(*accessTokenClaims)
+	if !ok || !token.Valid || claims.UserId == "" || claims.SessionId == "" {
+		return "", "", fmt.Errorf("invalid auth data")
+	}
+
+	// The standard checks skip claims that are missing, but tokens signed
+	// before access tokens expired have neither exp nor iat.
+	now := time.Now().Unix()
+	if !claims.VerifyExpiresAt(now, true) {
+		return "", "", fmt.Errorf("token has expired")
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid && claims["id"] == userId {
-		return true, nil
-	} else {
-		return false, fmt.Errorf("invalid auth data")
+	if !claims.VerifyIssuedAt(now, true) {
+		return "", "", fmt.Errorf("token used before issued")
 	}
+
+	if !claims.VerifyIssuer(JWT_ISSUER, true) {
+		return "", "", fmt.Errorf("invalid token issuer")
+	}
+
+	return claims.UserId, claims.SessionId, nil
 }
 
-func ExtractAuthData(header http.Header) (interfaces.AuthData, error) {
+func ExtractBearerToken(header http.Header) (string, error) {
 	tokenValue := header.Get("Authorization")
 	strArr := strings.Split(tokenValue, " ")
-	userIdValue := header.Get("id")
 
 	if len(strArr) == 2 {
-		return interfaces.AuthData{
-			Token:  strArr[1],
-			UserId: userIdValue,
-		}, nil
+		return strArr[1], nil
 	}
 
-	return interfaces.AuthData{}, fmt.Errorf("invalid auth data provided")
+	return "", fmt.Errorf("invalid auth data provided")
 }
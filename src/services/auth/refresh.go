@@ -0,0 +1,195 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+)
+
+const (
+	REFRESH_TOKEN_LIFETIME = 30 * 24 * time.Hour
+	OPAQUE_TOKEN_BYTES     = 32
+)
+
+// ErrInvalidRefreshToken is returned for refresh tokens that are unknown,
+// expired or already used, or whose session was revoked.
+var ErrInvalidRefreshToken = fmt.Errorf("invalid refresh token")
+
+// Only a hash of each refresh and personal access token is stored, so that a
+// leaked table cannot be used to log in.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func generateToken() (string, error) {
+	tokenBytes := make([]byte, OPAQUE_TOKEN_BYTES)
+
+	_, readErr := rand.Read(tokenBytes)
+	if readErr != nil {
+		return "", readErr
+	}
+
+	return base64.RawURLEncoding.EncodeToString(tokenBytes), nil
+}
+
+// IssueTokens logs a user in on a new device, starting a session that every
+// refresh token rotated from the one issued here belongs to.
+func IssueTokens(ex db.Executor, userId string, client interfaces.SessionClient) (interfaces.AuthTokens, error) {
+	var authTokens interfaces.AuthTokens
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		cleanUpErr := deleteStaleSessions(tx, userId)
+		if cleanUpErr != nil {
+			return cleanUpErr
+		}
+
+		sessionId, createSessionErr := createSession(tx, userId, client)
+		if createSessionErr != nil {
+			return createSessionErr
+		}
+
+		var issueErr error
+		authTokens, issueErr = issueTokens(tx, userId, sessionId)
+
+		return issueErr
+	})
+
+	return authTokens, transactErr
+}
+
+func issueTokens(ex db.Executor, userId string, sessionId string) (interfaces.AuthTokens, error) {
+	refreshToken, generateErr := generateToken()
+	if generateErr != nil {
+		return interfaces.AuthTokens{}, generateErr
+	}
+
+	now := time.Now()
+	sqlCommand := "INSERT INTO refresh_tokens (id, \"userId\", \"sessionId\", \"tokenHash\", \"createdAt\", \"expiresAt\", \"revokedAt\") VALUES ($1, $2, $3, $4, $5, $6, $7);"
+
+	_, execErr := ex.Exec(
+		sqlCommand,
+		utils.GenerateUid(),
+		userId,
+		sessionId,
+		hashToken(refreshToken),
+		now.Unix(),
+		now.Add(REFRESH_TOKEN_LIFETIME).Unix(),
+		-1,
+	)
+	if execErr != nil {
+		return interfaces.AuthTokens{}, execErr
+	}
+
+	accessToken, expiresAt, jwtErr := GenerateJwt(userId, sessionId)
+	if jwtErr != nil {
+		return interfaces.AuthTokens{}, jwtErr
+	}
+
+	return interfaces.AuthTokens{
+		Token:        accessToken,
+		UserId:       userId,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new access token and the
+// next refresh token of its session. A refresh token that was already used
+// must have leaked, so its whole session is revoked instead.
+func RefreshTokens(ex db.Executor, refreshToken string, client interfaces.SessionClient) (interfaces.AuthTokens, error) {
+	var newTokens interfaces.AuthTokens
+	isReused := false
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		var userId string
+		var sessionId string
+		var expiresAt int
+		var revokedAt int
+		var sessionRevokedAt int
+		sqlCommand := "SELECT r.\"userId\", r.\"sessionId\", r.\"expiresAt\", r.\"revokedAt\", s.\"revokedAt\" FROM refresh_tokens r INNER JOIN sessions s ON s.id = r.\"sessionId\" WHERE r.\"tokenHash\" = $1 FOR UPDATE OF r;"
+
+		queryErr := tx.QueryRow(sqlCommand, hashToken(refreshToken)).Scan(&userId, &sessionId, &expiresAt, &revokedAt, &sessionRevokedAt)
+		if queryErr == sql.ErrNoRows {
+			return ErrInvalidRefreshToken
+		}
+		if queryErr != nil {
+			return queryErr
+		}
+
+		now := int(time.Now().Unix())
+
+		if sessionRevokedAt != -1 {
+			return ErrInvalidRefreshToken
+		}
+
+		if revokedAt != -1 {
+			isReused = true
+			return revokeSession(tx, sessionId, now)
+		}
+
+		if expiresAt <= now {
+			return ErrInvalidRefreshToken
+		}
+
+		revokeCommand := "UPDATE refresh_tokens SET \"revokedAt\" = $1 WHERE \"tokenHash\" = $2;"
+
+		_, revokeErr := tx.Exec(revokeCommand, now, hashToken(refreshToken))
+		if revokeErr != nil {
+			return revokeErr
+		}
+
+		touchErr := touchSession(tx, sessionId, client, now)
+		if touchErr != nil {
+			return touchErr
+		}
+
+		var issueErr error
+		newTokens, issueErr = issueTokens(tx, userId, sessionId)
+
+		return issueErr
+	})
+	if transactErr != nil {
+		return interfaces.AuthTokens{}, transactErr
+	}
+
+	if isReused {
+		return interfaces.AuthTokens{}, ErrInvalidRefreshToken
+	}
+
+	return newTokens, nil
+}
+
+// RevokeRefreshToken ends the session a refresh token was issued to. If
+// everywhere is set, every session of its user is ended.
+func RevokeRefreshToken(ex db.Executor, refreshToken string, everywhere bool) error {
+	return db.Transact(ex, func(tx db.Executor) error {
+		var userId string
+		var sessionId string
+		sqlCommand := "SELECT \"userId\", \"sessionId\" FROM refresh_tokens WHERE \"tokenHash\" = $1;"
+
+		queryErr := tx.QueryRow(sqlCommand, hashToken(refreshToken)).Scan(&userId, &sessionId)
+		if queryErr == sql.ErrNoRows {
+			return ErrInvalidRefreshToken
+		}
+		if queryErr != nil {
+			return queryErr
+		}
+
+		now := int(time.Now().Unix())
+
+		if everywhere {
+			return RevokeAllSessions(tx, userId, now)
+		}
+
+		return revokeSession(tx, sessionId, now)
+	})
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+)
+
+const (
+	// How stale the last seen time of a session may get, so that not every
+	// request has to write to the database.
+	SESSION_TOUCH_INTERVAL = time.Minute
+
+	MAX_USER_AGENT_LENGTH = 512
+)
+
+// ErrSessionRevoked is returned for access tokens of sessions that were
+// logged out, revoked or cleaned up.
+var ErrSessionRevoked = fmt.Errorf("session has been revoked")
+
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) > MAX_USER_AGENT_LENGTH {
+		return userAgent[:MAX_USER_AGENT_LENGTH]
+	}
+
+	return userAgent
+}
+
+func createSession(ex db.Executor, userId string, client interfaces.SessionClient) (string, error) {
+	sessionId := utils.GenerateUid()
+	now := time.Now().Unix()
+	sqlCommand := "INSERT INTO sessions (id, \"userId\", device, \"userAgent\", ip, \"createdAt\", \"lastSeenAt\", \"revokedAt\") VALUES ($1, $2, $3, $4, $5, $6, $7, $8);"
+
+	_, execErr := ex.Exec(
+		sqlCommand,
+		sessionId,
+		userId,
+		client.Device,
+		truncateUserAgent(client.UserAgent),
+		client.Ip,
+		now,
+		now,
+		-1,
+	)
+
+	return sessionId, execErr
+}
+
+func touchSession(ex db.Executor, sessionId string, client interfaces.SessionClient, lastSeenAt int) error {
+	sqlCommand := "UPDATE sessions SET \"userAgent\" = $1, ip = $2, \"lastSeenAt\" = $3 WHERE id = $4;"
+
+	_, execErr := ex.Exec(sqlCommand, truncateUserAgent(client.UserAgent), client.Ip, lastSeenAt, sessionId)
+
+	return execErr
+}
+
+// VerifySession makes sure the session of an access token is still active,
+// and records that it was just seen from the given address.
+func VerifySession(ex db.Executor, sessionId string, userId string, ip string) error {
+	var revokedAt int
+	var lastSeenAt int
+	var lastIp string
+	sqlCommand := "SELECT \"revokedAt\", \"lastSeenAt\", ip FROM sessions WHERE id = $1 AND \"userId\" = $2;"
+
+	queryErr := ex.QueryRow(sqlCommand, sessionId, userId).Scan(&revokedAt, &lastSeenAt, &lastIp)
+	if queryErr == sql.ErrNoRows {
+		return ErrSessionRevoked
+	}
+	if queryErr != nil {
+		return queryErr
+	}
+
+	if revokedAt != -1 {
+		return ErrSessionRevoked
+	}
+
+	now := time.Now()
+	if lastIp == ip && now.Sub(time.Unix(int64(lastSeenAt), 0)) < SESSION_TOUCH_INTERVAL {
+		return nil
+	}
+
+	touchCommand := "UPDATE sessions SET ip = $1, \"lastSeenAt\" = $2 WHERE id = $3;"
+
+	_, execErr := ex.Exec(touchCommand, ip, now.Unix(), sessionId)
+
+	return execErr
+}
+
+// RetrieveSessionsByUserId returns the sessions a user is still logged in
+// with, most recently seen first.
+func RetrieveSessionsByUserId(userId string) ([]interfaces.Session, error) {
+	sessions := []interfaces.Session{}
+	sqlCommand := "SELECT id, device, \"userAgent\", ip, \"createdAt\", \"lastSeenAt\" FROM sessions WHERE \"userId\" = $1 AND \"revokedAt\" = -1 AND \"lastSeenAt\" >= $2 ORDER BY \"lastSeenAt\" DESC;"
+
+	rows, queryErr := db.Database.Query(sqlCommand, userId, time.Now().Add(-REFRESH_TOKEN_LIFETIME).Unix())
+	if queryErr != nil {
+		return sessions, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		session := interfaces.Session{}
+		scanErr := rows.Scan(
+			&session.Id,
+			&session.Device,
+			&session.UserAgent,
+			&session.Ip,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+		)
+		if scanErr != nil {
+			return sessions, scanErr
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return sessions, rowsErr
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs a user out of one of their sessions. Its access tokens
+// are rejected from the next request on.
+func RevokeSession(ex db.Executor, userId string, sessionId string) (interfaces.Session, error) {
+	var revokedSession interfaces.Session
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		sqlCommand := "SELECT id, device, \"userAgent\", ip, \"createdAt\", \"lastSeenAt\" FROM sessions WHERE id = $1 AND \"userId\" = $2 AND \"revokedAt\" = -1 FOR UPDATE;"
+
+		queryErr := tx.QueryRow(sqlCommand, sessionId, userId).Scan(
+			&revokedSession.Id,
+			&revokedSession.Device,
+			&revokedSession.UserAgent,
+			&revokedSession.Ip,
+			&revokedSession.CreatedAt,
+			&revokedSession.LastSeenAt,
+		)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		return revokeSession(tx, sessionId, int(time.Now().Unix()))
+	})
+
+	return revokedSession, transactErr
+}
+
+func RevokeAllSessions(ex db.Executor, userId string, revokedAt int) error {
+	sqlCommand := "UPDATE sessions SET \"revokedAt\" = $1 WHERE \"userId\" = $2 AND \"revokedAt\" = -1;"
+
+	_, execErr := ex.Exec(sqlCommand, revokedAt, userId)
+	if execErr != nil {
+		return execErr
+	}
+
+	revokeTokensCommand := "UPDATE refresh_tokens SET \"revokedAt\" = $1 WHERE \"userId\" = $2 AND \"revokedAt\" = -1;"
+
+	_, revokeTokensErr := ex.Exec(revokeTokensCommand, revokedAt, userId)
+
+	return revokeTokensErr
+}
+
+func revokeSession(ex db.Executor, sessionId string, revokedAt int) error {
+	sqlCommand := "UPDATE sessions SET \"revokedAt\" = $1 WHERE id = $2 AND \"revokedAt\" = -1;"
+
+	_, execErr := ex.Exec(sqlCommand, revokedAt, sessionId)
+	if execErr != nil {
+		return execErr
+	}
+
+	revokeTokensCommand := "UPDATE refresh_tokens SET \"revokedAt\" = $1 WHERE \"sessionId\" = $2 AND \"revokedAt\" = -1;"
+
+	_, revokeTokensErr := ex.Exec(revokeTokensCommand, revokedAt, sessionId)
+
+	return revokeTokensErr
+}
+
+// deleteStaleSessions forgets the sessions of a user that have not been seen
+// for longer than a refresh token lives, together with their refresh tokens.
+func deleteStaleSessions(ex db.Executor, userId string) error {
+	staleBefore := time.Now().Add(-REFRESH_TOKEN_LIFETIME).Unix()
+	deleteTokensCommand := "DELETE FROM refresh_tokens WHERE \"userId\" = $1 AND (\"expiresAt\" < $2 OR \"sessionId\" IN (SELECT id FROM sessions WHERE \"userId\" = $1 AND \"lastSeenAt\" < $3));"
+
+	_, deleteTokensErr := ex.Exec(deleteTokensCommand, userId, time.Now().Unix(), staleBefore)
+	if deleteTokensErr != nil {
+		return deleteTokensErr
+	}
+
+	sqlCommand := "DELETE FROM sessions WHERE \"userId\" = $1 AND \"lastSeenAt\" < $2;"
+
+	_, execErr := ex.Exec(sqlCommand, userId, staleBefore)
+
+	return execErr
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+	"github.com/lib/pq"
+)
+
+const (
+	// Tells personal access tokens apart from access tokens of sessions, and
+	// makes them easy to spot in leaked code.
+	PERSONAL_ACCESS_TOKEN_PREFIX = "dgp_"
+
+	PERSONAL_ACCESS_TOKEN_COLUMNS = "id, name, scopes, \"createdAt\", \"expiresAt\", \"lastUsedAt\""
+)
+
+// ErrInvalidPersonalAccessToken is returned for personal access tokens that
+// are unknown, expired or deleted.
+var ErrInvalidPersonalAccessToken = fmt.Errorf("invalid personal access token")
+
+func personalAccessTokenFields(token *interfaces.PersonalAccessToken, scopes *pq.StringArray) []interface{} {
+	return []interface{}{
+		&token.Id,
+		&token.Name,
+		scopes,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+	}
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PERSONAL_ACCESS_TOKEN_PREFIX)
+}
+
+func CreatePersonalAccessToken(ex db.Executor, userId string, name string, scopes []string, expiresAt int) (interfaces.CreatedPersonalAccessToken, error) {
+	secret, generateErr := generateToken()
+	if generateErr != nil {
+		return interfaces.CreatedPersonalAccessToken{}, generateErr
+	}
+
+	newToken := interfaces.CreatedPersonalAccessToken{
+		PersonalAccessToken: interfaces.PersonalAccessToken{
+			Id:         utils.GenerateUid(),
+			Name:       name,
+			Scopes:     scopes,
+			CreatedAt:  int(time.Now().Unix()),
+			ExpiresAt:  expiresAt,
+			LastUsedAt: -1,
+		},
+		Token: PERSONAL_ACCESS_TOKEN_PREFIX + secret,
+	}
+
+	sqlCommand := "INSERT INTO personal_access_tokens (id, \"userId\", name, \"tokenHash\", scopes, \"createdAt\", \"expiresAt\", \"lastUsedAt\") VALUES ($1, $2, $3, $4, $5, $6, $7, $8);"
+
+	_, execErr := ex.Exec(
+		sqlCommand,
+		newToken.Id,
+		userId,
+		newToken.Name,
+		hashToken(newToken.Token),
+		pq.Array(newToken.Scopes),
+		newToken.CreatedAt,
+		newToken.ExpiresAt,
+		newToken.LastUsedAt,
+	)
+
+	return newToken, execErr
+}
+
+// ValidatePersonalAccessToken returns the user a personal access token acts
+// for and the scopes it is limited to, and records that it was just used.
+func ValidatePersonalAccessToken(ex db.Executor, token string) (string, []string, error) {
+	var tokenId string
+	var userId string
+	var scopes pq.StringArray
+	var expiresAt int
+	var lastUsedAt int
+	sqlCommand := "SELECT id, \"userId\", scopes, \"expiresAt\", \"lastUsedAt\" FROM personal_access_tokens WHERE \"tokenHash\" = $1;"
+
+	queryErr := ex.QueryRow(sqlCommand, hashToken(token)).Scan(&tokenId, &userId, &scopes, &expiresAt, &lastUsedAt)
+	if queryErr == sql.ErrNoRows {
+		return "", nil, ErrInvalidPersonalAccessToken
+	}
+	if queryErr != nil {
+		return "", nil, queryErr
+	}
+
+	now := time.Now()
+	if expiresAt != -1 && int64(expiresAt) <= now.Unix() {
+		return "", nil, ErrInvalidPersonalAccessToken
+	}
+
+	if now.Sub(time.Unix(int64(lastUsedAt), 0)) >= SESSION_TOUCH_INTERVAL {
+		touchCommand := "UPDATE personal_access_tokens SET \"lastUsedAt\" = $1 WHERE id = $2;"
+
+		_, execErr := ex.Exec(touchCommand, now.Unix(), tokenId)
+		if execErr != nil {
+			return "", nil, execErr
+		}
+	}
+
+	return userId, scopes, nil
+}
+
+func RetrievePersonalAccessTokensByUserId(userId string) ([]interfaces.PersonalAccessToken, error) {
+	tokens := []interfaces.PersonalAccessToken{}
+	sqlCommand := "SELECT " + PERSONAL_ACCESS_TOKEN_COLUMNS + " FROM personal_access_tokens WHERE \"userId\" = $1 ORDER BY \"createdAt\" DESC;"
+
+	rows, queryErr := db.Database.Query(sqlCommand, userId)
+	if queryErr != nil {
+		return tokens, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		token := interfaces.PersonalAccessToken{}
+		var scopes pq.StringArray
+
+		scanErr := rows.Scan(personalAccessTokenFields(&token, &scopes)...)
+		if scanErr != nil {
+			return tokens, scanErr
+		}
+
+		token.Scopes = scopes
+		tokens = append(tokens, token)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return tokens, rowsErr
+	}
+
+	return tokens, nil
+}
+
+func DeletePersonalAccessToken(ex db.Executor, userId string, tokenId string) (interfaces.PersonalAccessToken, error) {
+	var deletedToken interfaces.PersonalAccessToken
+	var scopes pq.StringArray
+	sqlCommand := "DELETE FROM personal_access_tokens WHERE id = $1 AND \"userId\" = $2 RETURNING " + PERSONAL_ACCESS_TOKEN_COLUMNS + ";"
+
+	queryErr := ex.QueryRow(sqlCommand, tokenId, userId).Scan(personalAccessTokenFields(&deletedToken, &scopes)...)
+	deletedToken.Scopes = scopes
+
+	return deletedToken, queryErr
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+)
+
+func CreateComment(ex db.Executor, comment interfaces.CommentCreationData) (interfaces.Comment, error) {
+	sqlCommand := "INSERT INTO comments (id, \"taskId\", author, body, \"createdAt\", \"updatedAt\") VALUES ($1, $2, $3, $4, $5, $6);"
+
+	now := int(time.Now().Unix())
+	newComment := interfaces.Comment{
+		Id:        utils.GenerateUid(),
+		TaskId:    comment.TaskId,
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	_, execErr := ex.Exec(
+		sqlCommand,
+		newComment.Id,
+		newComment.TaskId,
+		newComment.Author,
+		newComment.Body,
+		newComment.CreatedAt,
+		newComment.UpdatedAt,
+	)
+
+	return newComment, execErr
+}
+
+func EditComment(ex db.Executor, comment interfaces.CommentEditionData) (interfaces.Comment, error) {
+	var updatedComment interfaces.Comment
+	sqlCommand := "UPDATE comments SET body = $1, \"updatedAt\" = $2 WHERE id = $3 RETURNING *;"
+
+	queryErr := ex.QueryRow(
+		sqlCommand,
+		comment.Body,
+		int(time.Now().Unix()),
+		comment.Id,
+	).Scan(
+		&updatedComment.Id,
+		&updatedComment.TaskId,
+		&updatedComment.Author,
+		&updatedComment.Body,
+		&updatedComment.CreatedAt,
+		&updatedComment.UpdatedAt,
+	)
+
+	return updatedComment, queryErr
+}
+
+func DeleteComment(ex db.Executor, commentId string) (interfaces.Comment, error) {
+	var deletedComment interfaces.Comment
+	sqlCommand := "DELETE FROM comments WHERE id = $1 RETURNING *;"
+
+	queryErr := ex.QueryRow(
+		sqlCommand,
+		commentId,
+	).Scan(
+		&deletedComment.Id,
+		&deletedComment.TaskId,
+		&deletedComment.Author,
+		&deletedComment.Body,
+		&deletedComment.CreatedAt,
+		&deletedComment.UpdatedAt,
+	)
+
+	return deletedComment, queryErr
+}
+
+func DeleteCommentsFromTask(ex db.Executor, taskId string) error {
+	sqlCommand := "DELETE FROM comments WHERE \"taskId\" = $1;"
+
+	_, execErr := ex.Exec(sqlCommand, taskId)
+	if execErr != nil {
+		return execErr
+	}
+
+	return nil
+}
+
+func DeleteCommentsFromList(ex db.Executor, listId string) error {
+	sqlCommand := "DELETE FROM comments WHERE \"taskId\" IN (SELECT id FROM tasks WHERE \"listId\" = $1);"
+
+	_, execErr := ex.Exec(sqlCommand, listId)
+	if execErr != nil {
+		return execErr
+	}
+
+	return nil
+}
+
+func RetrieveCommentById(commentId string) (interfaces.Comment, error) {
+	var comment interfaces.Comment
+	sqlCommand := "SELECT * FROM comments WHERE id = $1"
+
+	queryErr := db.Database.QueryRow(sqlCommand, commentId).Scan(
+		&comment.Id,
+		&comment.TaskId,
+		&comment.Author,
+		&comment.Body,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+
+	return comment, queryErr
+}
+
+func RetrieveCommentsByTaskId(taskId string) ([]interfaces.Comment, error) {
+	var comments []interfaces.Comment
+	sqlCommand := "SELECT * FROM comments WHERE \"taskId\" = $1 ORDER BY \"createdAt\" ASC"
+
+	rows, queryErr := db.Database.Query(sqlCommand, taskId)
+	if queryErr != nil {
+		return comments, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		comment := interfaces.Comment{}
+		scanErr := rows.Scan(
+			&comment.Id,
+			&comment.TaskId,
+			&comment.Author,
+			&comment.Body,
+			&comment.CreatedAt,
+			&comment.UpdatedAt,
+		)
+		if scanErr != nil {
+			return comments, scanErr
+		}
+
+		comments = append(comments, comment)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return comments, rowsErr
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+)
+
+const (
+	// Events a subscriber may fall behind by before it is dropped. Its client
+	// then reconnects and refetches instead of silently missing changes.
+	SUBSCRIPTION_BUFFER_SIZE = 64
+
+	LIST_TOPIC_PREFIX = "list:"
+	USER_TOPIC_PREFIX = "user:"
+)
+
+// Subscription receives the changes of the lists it was opened for, and the
+// membership changes that concern its user. Events is closed once the
+// subscription is dropped.
+type Subscription struct {
+	UserId string
+	Events chan interfaces.ChangeEvent
+	topics []string
+	closed bool
+}
+
+var hub = struct {
+	sync.Mutex
+	topics map[string]map[*Subscription]bool
+}{
+	topics: map[string]map[*Subscription]bool{},
+}
+
+func Subscribe(userId string, listIds []string) *Subscription {
+	subscription := &Subscription{
+		UserId: userId,
+		Events: make(chan interfaces.ChangeEvent, SUBSCRIPTION_BUFFER_SIZE),
+		topics: []string{USER_TOPIC_PREFIX + userId},
+	}
+
+	for _, listId := range listIds {
+		if !utils.Contains(subscription.topics, LIST_TOPIC_PREFIX+listId) {
+			subscription.topics = append(subscription.topics, LIST_TOPIC_PREFIX+listId)
+		}
+	}
+
+	hub.Lock()
+	defer hub.Unlock()
+
+	for _, topic := range subscription.topics {
+		if hub.topics[topic] == nil {
+			hub.topics[topic] = map[*Subscription]bool{}
+		}
+		hub.topics[topic][subscription] = true
+	}
+
+	return subscription
+}
+
+func Unsubscribe(subscription *Subscription) {
+	hub.Lock()
+	defer hub.Unlock()
+
+	drop(subscription)
+}
+
+// Leave stops delivering the events of a list to a subscription.
+func Leave(subscription *Subscription, listId string) {
+	hub.Lock()
+	defer hub.Unlock()
+
+	removeFromTopic(subscription, LIST_TOPIC_PREFIX+listId)
+}
+
+func PublishTask(changeType string, actorId string, task interfaces.Task, otherListIds ...string) {
+	event := newEvent(changeType, actorId, task.ListId)
+	event.Task = &task
+
+	topics := []string{LIST_TOPIC_PREFIX + task.ListId}
+	for _, listId := range otherListIds {
+		topics = append(topics, LIST_TOPIC_PREFIX+listId)
+	}
+
+	publish(event, topics)
+}
+
+func PublishTasks(changeType string, actorId string, listId string, tasks []interfaces.Task) {
+	event := newEvent(changeType, actorId, listId)
+	event.Tasks = tasks
+
+	publish(event, []string{LIST_TOPIC_PREFIX + listId})
+}
+
+func PublishList(changeType string, actorId string, list interfaces.List) {
+	event := newEvent(changeType, actorId, list.Id)
+	event.List = &list
+
+	publish(event, []string{LIST_TOPIC_PREFIX + list.Id})
+}
+
+// PublishListMembers announces new list members to the list, and also to the
+// users who were added or removed, as they are not subscribed to it yet or
+// any more.
+func PublishListMembers(actorId string, list interfaces.List, previousMembers []string) {
+	event := newEvent(interfaces.CHANGE_LIST_MEMBERS, actorId, list.Id)
+	event.List = &list
+
+	topics := []string{LIST_TOPIC_PREFIX + list.Id}
+	for _, memberId := range list.Members {
+		if !utils.Contains(previousMembers, memberId) {
+			topics = append(topics, USER_TOPIC_PREFIX+memberId)
+		}
+	}
+	for _, memberId := range previousMembers {
+		if !utils.Contains(list.Members, memberId) {
+			topics = append(topics, USER_TOPIC_PREFIX+memberId)
+		}
+	}
+
+	publish(event, topics)
+}
+
+// PublishListRestored announces a list taken out of the trash to the users
+// it belongs to, as their subscriptions left the list when it was deleted.
+func PublishListRestored(actorId string, list interfaces.List) {
+	event := newEvent(interfaces.CHANGE_LIST_UPDATED, actorId, list.Id)
+	event.List = &list
+
+	topics := []string{LIST_TOPIC_PREFIX + list.Id, USER_TOPIC_PREFIX + list.Owner}
+	for _, memberId := range list.Members {
+		topics = append(topics, USER_TOPIC_PREFIX+memberId)
+	}
+
+	publish(event, topics)
+}
+
+func newEvent(changeType string, actorId string, listId string) interfaces.ChangeEvent {
+	return interfaces.ChangeEvent{
+		Type:      changeType,
+		ListId:    listId,
+		Actor:     actorId,
+		Timestamp: int(time.Now().Unix()),
+	}
+}
+
+func publish(event interfaces.ChangeEvent, topics []string) {
+	hub.Lock()
+	defer hub.Unlock()
+
+	delivered := map[*Subscription]bool{}
+	for _, topic := range topics {
+		for subscription := range hub.topics[topic] {
+			if delivered[subscription] {
+				continue
+			}
+			delivered[subscription] = true
+
+			select {
+			case subscription.Events <- event:
+			default:
+				drop(subscription)
+			}
+		}
+	}
+}
+
+// drop must be called with the hub locked.
+func drop(subscription *Subscription) {
+	if subscription.closed {
+		return
+	}
+
+	for _, topic := range subscription.topics {
+		removeFromTopic(subscription, topic)
+	}
+
+	subscription.closed = true
+	close(subscription.Events)
+}
+
+// removeFromTopic must be called with the hub locked.
+func removeFromTopic(subscription *Subscription, topic string) {
+	delete(hub.topics[topic], subscription)
+	if len(hub.topics[topic]) == 0 {
+		delete(hub.topics, topic)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+)
+
+func CreateFilter(ex db.Executor, ownerId string, name string, query string) (interfaces.Filter, error) {
+	sqlCommand := "INSERT INTO filters (id, owner, name, query) VALUES ($1, $2, $3, $4);"
+
+	newFilter := interfaces.Filter{
+		Id:    utils.GenerateUid(),
+		Owner: ownerId,
+		Name:  name,
+		Query: query,
+	}
+
+	_, execErr := ex.Exec(sqlCommand, newFilter.Id, newFilter.Owner, newFilter.Name, newFilter.Query)
+
+	return newFilter, execErr
+}
+
+func EditFilter(ex db.Executor, filterId string, name string, query string) (interfaces.Filter, error) {
+	var updatedFilter interfaces.Filter
+	sqlCommand := "UPDATE filters SET name = $1, query = $2 WHERE id = $3 RETURNING id, owner, name, query;"
+
+	queryErr := ex.QueryRow(
+		sqlCommand,
+		name,
+		query,
+		filterId,
+	).Scan(
+		&updatedFilter.Id,
+		&updatedFilter.Owner,
+		&updatedFilter.Name,
+		&updatedFilter.Query,
+	)
+
+	return updatedFilter, queryErr
+}
+
+func DeleteFilter(ex db.Executor, filterId string) (interfaces.Filter, error) {
+	var deletedFilter interfaces.Filter
+	sqlCommand := "DELETE FROM filters WHERE id = $1 RETURNING id, owner, name, query;"
+
+	queryErr := ex.QueryRow(
+		sqlCommand,
+		filterId,
+	).Scan(
+		&deletedFilter.Id,
+		&deletedFilter.Owner,
+		&deletedFilter.Name,
+		&deletedFilter.Query,
+	)
+
+	return deletedFilter, queryErr
+}
+
+func RetrieveFilterById(filterId string) (interfaces.Filter, error) {
+	var filter interfaces.Filter
+	sqlCommand := "SELECT id, owner, name, query FROM filters WHERE id = $1"
+
+	queryErr := db.Database.QueryRow(sqlCommand, filterId).Scan(
+		&filter.Id,
+		&filter.Owner,
+		&filter.Name,
+		&filter.Query,
+	)
+
+	return filter, queryErr
+}
+
+func RetrieveFiltersByOwner(ownerId string) ([]interfaces.Filter, error) {
+	filters := []interfaces.Filter{}
+	sqlCommand := "SELECT id, owner, name, query FROM filters WHERE owner = $1 ORDER BY name ASC"
+
+	rows, queryErr := db.Database.Query(sqlCommand, ownerId)
+	if queryErr != nil {
+		return filters, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		filter := interfaces.Filter{}
+		scanErr := rows.Scan(
+			&filter.Id,
+			&filter.Owner,
+			&filter.Name,
+			&filter.Query,
+		)
+		if scanErr != nil {
+			return filters, scanErr
+		}
+
+		filters = append(filters, filter)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return filters, rowsErr
+	}
+
+	return filters, nil
+}
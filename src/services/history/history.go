@@ -0,0 +1,194 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+)
+
+// Fields that are either identity, derived from other tables or implied by the
+// event type, and so never show up in a diff.
+var untrackedTaskFields = map[string]bool{
+	"id":        true,
+	"owner":     true,
+	"blockedBy": true,
+	"blocking":  true,
+	"blocked":   true,
+	"deletedAt": true,
+	"deletedBy": true,
+	"updatedAt": true,
+	"rank":      true, // moves show up as listOrder changes
+	"version":   true,
+}
+
+func RecordTaskEvent(ex db.Executor, actorId string, eventType string, before *interfaces.Task, after *interfaces.Task) error {
+	changes := DiffTasks(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	event := interfaces.TaskEvent{
+		Id:        utils.GenerateUid(),
+		Actor:     actorId,
+		Type:      eventType,
+		Timestamp: int(time.Now().Unix()),
+		Changes:   changes,
+	}
+
+	if after != nil {
+		event.TaskId = after.Id
+		event.ListId = after.ListId
+	} else {
+		event.TaskId = before.Id
+		event.ListId = before.ListId
+	}
+
+	return insertTaskEvent(ex, event)
+}
+
+// RecordSubtaskEvent records the creation (nil before), edit or deletion (nil
+// after) of a subtask in the history of its task, as a change of "subtask".
+func RecordSubtaskEvent(ex db.Executor, actorId string, task interfaces.Task, before *interfaces.Subtask, after *interfaces.Subtask) error {
+	change := interfaces.TaskFieldChange{}
+	if before != nil {
+		change.Before = *before
+	}
+	if after != nil {
+		change.After = *after
+	}
+
+	return insertTaskEvent(ex, interfaces.TaskEvent{
+		Id:        utils.GenerateUid(),
+		TaskId:    task.Id,
+		ListId:    task.ListId,
+		Actor:     actorId,
+		Type:      interfaces.TASK_EVENT_SUBTASK,
+		Timestamp: int(time.Now().Unix()),
+		Changes:   map[string]interfaces.TaskFieldChange{"subtask": change},
+	})
+}
+
+func insertTaskEvent(ex db.Executor, event interfaces.TaskEvent) error {
+	changesJson, marshalErr := json.Marshal(event.Changes)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	sqlCommand := "INSERT INTO task_events (id, \"taskId\", \"listId\", actor, type, timestamp, changes) VALUES ($1, $2, $3, $4, $5, $6, $7);"
+
+	_, execErr := ex.Exec(
+		sqlCommand,
+		event.Id,
+		event.TaskId,
+		event.ListId,
+		event.Actor,
+		event.Type,
+		event.Timestamp,
+		changesJson,
+	)
+
+	return execErr
+}
+
+// DiffTasks returns the changed fields between two versions of a task. A nil
+// before (creation) or after (deletion) records every tracked field.
+func DiffTasks(before *interfaces.Task, after *interfaces.Task) map[string]interfaces.TaskFieldChange {
+	changes := make(map[string]interfaces.TaskFieldChange)
+	taskType := reflect.TypeOf(interfaces.Task{})
+
+	for i := 0; i < taskType.NumField(); i++ {
+		field := taskType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if untrackedTaskFields[name] {
+			continue
+		}
+
+		var beforeValue, afterValue interface{}
+		if before != nil {
+			beforeValue = reflect.ValueOf(*before).Field(i).Interface()
+		}
+		if after != nil {
+			afterValue = reflect.ValueOf(*after).Field(i).Interface()
+		}
+
+		if before != nil && after != nil && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+
+		changes[name] = interfaces.TaskFieldChange{
+			Before: beforeValue,
+			After:  afterValue,
+		}
+	}
+
+	return changes
+}
+
+func DeleteTaskEvents(ex db.Executor, taskId string) error {
+	sqlCommand := "DELETE FROM task_events WHERE \"taskId\" = $1;"
+
+	_, execErr := ex.Exec(sqlCommand, taskId)
+
+	return execErr
+}
+
+// DeleteTaskEventsFromList deletes the history of every task of a list,
+// including events recorded while a task still lived in another list.
+func DeleteTaskEventsFromList(ex db.Executor, listId string) error {
+	sqlCommand := "DELETE FROM task_events WHERE \"taskId\" IN (SELECT id FROM tasks WHERE \"listId\" = $1);"
+
+	_, execErr := ex.Exec(sqlCommand, listId)
+
+	return execErr
+}
+
+// RetrieveTaskEvents returns the events of a task in the order they were
+// recorded. Timestamps only have second resolution, so events recorded in
+// the same second are ordered by their sequence number instead.
+func RetrieveTaskEvents(taskId string) ([]interfaces.TaskEvent, error) {
+	var events []interfaces.TaskEvent
+	sqlCommand := "SELECT id, \"taskId\", \"listId\", actor, type, timestamp, changes FROM task_events WHERE \"taskId\" = $1 ORDER BY sequence ASC"
+
+	rows, queryErr := db.Database.Query(sqlCommand, taskId)
+	if queryErr != nil {
+		return events, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event := interfaces.TaskEvent{}
+		var changesJson []byte
+
+		scanErr := rows.Scan(
+			&event.Id,
+			&event.TaskId,
+			&event.ListId,
+			&event.Actor,
+			&event.Type,
+			&event.Timestamp,
+			&changesJson,
+		)
+		if scanErr != nil {
+			return events, scanErr
+		}
+
+		unmarshalErr := json.Unmarshal(changesJson, &event.Changes)
+		if unmarshalErr != nil {
+			return events, unmarshalErr
+		}
+
+		events = append(events, event)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return events, rowsErr
+	}
+
+	return events, nil
+}
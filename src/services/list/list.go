@@ -1,92 +1,198 @@
 package service
 
 import (
+	"time"
+
 	"github.com/beebeeoii/do-gether/db"
 	"github.com/beebeeoii/do-gether/interfaces"
 	utils "github.com/beebeeoii/do-gether/services/utils"
 	"github.com/lib/pq"
 )
 
-func CreateList(name string, ownerId string, private bool) (interfaces.List, error) {
-	sqlCommand := "INSERT INTO lists (id, name, owner, private, members) VALUES ($1, $2, $3, $4, $5);"
+const LIST_COLUMNS = "id, name, owner, private, members, \"deletedAt\", \"deletedBy\", \"archivedAt\", \"autoArchiveDays\", version"
+
+// listFields returns the scan destinations of a list in LIST_COLUMNS order.
+func listFields(list *interfaces.List) []interface{} {
+	return []interface{}{
+		&list.Id,
+		&list.Name,
+		&list.Owner,
+		&list.Private,
+		pq.Array(&list.Members),
+		&list.DeletedAt,
+		&list.DeletedBy,
+		&list.ArchivedAt,
+		&list.AutoArchiveDays,
+		&list.Version,
+	}
+}
+
+func CreateList(ex db.Executor, name string, ownerId string, private bool) (interfaces.List, error) {
+	sqlCommand := "INSERT INTO lists (" + LIST_COLUMNS + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);"
 
 	newList := interfaces.List{
-		Id:      utils.GenerateUid(),
-		Name:    name,
-		Owner:   ownerId,
-		Private: private,
-		Members: []string{},
+		Id:              utils.GenerateUid(),
+		Name:            name,
+		Owner:           ownerId,
+		Private:         private,
+		Members:         []string{},
+		DeletedAt:       -1,
+		DeletedBy:       "",
+		ArchivedAt:      -1,
+		AutoArchiveDays: -1,
+		Version:         1,
 	}
-	_, execErr := db.Database.Exec(sqlCommand, newList.Id, newList.Name, newList.Owner, newList.Private, pq.Array(newList.Members))
+	_, execErr := ex.Exec(sqlCommand, newList.Id, newList.Name, newList.Owner, newList.Private, pq.Array(newList.Members), newList.DeletedAt, newList.DeletedBy, newList.ArchivedAt, newList.AutoArchiveDays, newList.Version)
 
 	return newList, execErr
 }
 
-func EditList(id string, name string, private bool) (interfaces.List, error) {
+func EditList(ex db.Executor, id string, name string, private bool, version int) (interfaces.List, error) {
 	var updatedList interfaces.List
-	sqlCommand := "UPDATE lists set name = $1, private = $2 WHERE id = $3 RETURNING *;"
 
-	queryErr := db.Database.QueryRow(
-		sqlCommand,
-		name,
-		private,
-		id,
-	).Scan(
-		&updatedList.Id,
-		&updatedList.Name,
-		&updatedList.Owner,
-		&updatedList.Private,
-		pq.Array(&updatedList.Members),
-	)
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockListAtVersion(tx, id, version)
+		if lockErr != nil {
+			return lockErr
+		}
 
-	return updatedList, queryErr
+		sqlCommand := "UPDATE lists set version = version + 1, name = $1, private = $2 WHERE id = $3 AND \"deletedAt\" = -1 RETURNING " + LIST_COLUMNS + ";"
+
+		return tx.QueryRow(
+			sqlCommand,
+			name,
+			private,
+			id,
+		).Scan(listFields(&updatedList)...)
+	})
+
+	return updatedList, transactErr
 }
 
-func EditListMembers(id string, members []string) (interfaces.List, error) {
+func EditListMembers(ex db.Executor, id string, members []string, version int) (interfaces.List, error) {
 	var updatedList interfaces.List
-	sqlCommand := "UPDATE lists set members = $1 WHERE id = $2 RETURNING *;"
 
-	queryErr := db.Database.QueryRow(
-		sqlCommand,
-		pq.Array(members),
-		id,
-	).Scan(
-		&updatedList.Id,
-		&updatedList.Name,
-		&updatedList.Owner,
-		&updatedList.Private,
-		pq.Array(&updatedList.Members),
-	)
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockListAtVersion(tx, id, version)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		sqlCommand := "UPDATE lists set version = version + 1, members = $1 WHERE id = $2 AND \"deletedAt\" = -1 RETURNING " + LIST_COLUMNS + ";"
+
+		return tx.QueryRow(
+			sqlCommand,
+			pq.Array(members),
+			id,
+		).Scan(listFields(&updatedList)...)
+	})
+
+	return updatedList, transactErr
+}
+
+func ArchiveList(ex db.Executor, listId string, archived bool, version int) (interfaces.List, error) {
+	var updatedList interfaces.List
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockListAtVersion(tx, listId, version)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		sqlCommand := "UPDATE lists SET version = version + 1, \"archivedAt\" = -1 WHERE id = $1 AND \"deletedAt\" = -1 RETURNING " + LIST_COLUMNS + ";"
+		sqlParams := []interface{}{listId}
+
+		if archived {
+			sqlCommand = "UPDATE lists SET version = version + 1, \"archivedAt\" = CASE WHEN \"archivedAt\" = -1 THEN $2 ELSE \"archivedAt\" END WHERE id = $1 AND \"deletedAt\" = -1 RETURNING " + LIST_COLUMNS + ";"
+			sqlParams = append(sqlParams, int(time.Now().Unix()))
+		}
+
+		return tx.QueryRow(sqlCommand, sqlParams...).Scan(listFields(&updatedList)...)
+	})
 
-	return updatedList, queryErr
+	return updatedList, transactErr
 }
 
-func DeleteList(listId string) (interfaces.List, error) {
+func EditListAutoArchive(ex db.Executor, listId string, autoArchiveDays int, version int) (interfaces.List, error) {
+	var updatedList interfaces.List
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockListAtVersion(tx, listId, version)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		sqlCommand := "UPDATE lists SET version = version + 1, \"autoArchiveDays\" = $1 WHERE id = $2 AND \"deletedAt\" = -1 RETURNING " + LIST_COLUMNS + ";"
+
+		return tx.QueryRow(sqlCommand, autoArchiveDays, listId).Scan(listFields(&updatedList)...)
+	})
+
+	return updatedList, transactErr
+}
+
+// DeleteList moves a list to the trash. Its tasks are left untouched and
+// become reachable again once the list is restored.
+func DeleteList(ex db.Executor, actorId string, listId string) (interfaces.List, error) {
 	var deletedList interfaces.List
-	sqlCommand := "DELETE FROM lists WHERE id = $1 RETURNING *;"
+	sqlCommand := "UPDATE lists SET version = version + 1, \"deletedAt\" = $1, \"deletedBy\" = $2 WHERE id = $3 AND \"deletedAt\" = -1 RETURNING " + LIST_COLUMNS + ";"
 
-	queryErr := db.Database.QueryRow(
+	queryErr := ex.QueryRow(
 		sqlCommand,
+		int(time.Now().Unix()),
+		actorId,
 		listId,
-	).Scan(
-		&deletedList.Id,
-		&deletedList.Name,
-		&deletedList.Owner,
-		&deletedList.Private,
-		pq.Array(&deletedList.Members),
-	)
+	).Scan(listFields(&deletedList)...)
 
 	return deletedList, queryErr
 }
 
-func RetrieveListsByUserId(ownerId string, userId string) ([]interfaces.BasicListData, error) {
+func RestoreList(ex db.Executor, listId string) (interfaces.List, error) {
+	var restoredList interfaces.List
+	sqlCommand := "UPDATE lists SET version = version + 1, \"deletedAt\" = -1, \"deletedBy\" = '' WHERE id = $1 AND \"deletedAt\" <> -1 RETURNING " + LIST_COLUMNS + ";"
+
+	queryErr := ex.QueryRow(sqlCommand, listId).Scan(listFields(&restoredList)...)
+
+	return restoredList, queryErr
+}
+
+// lockListAtVersion locks a list for the rest of the transaction, failing with
+// db.ErrVersionConflict if it is no longer at the given version.
+func lockListAtVersion(tx db.Executor, listId string, version int) error {
+	var currentVersion int
+	sqlCommand := "SELECT version FROM lists WHERE id = $1 AND \"deletedAt\" = -1 FOR UPDATE"
+
+	queryErr := tx.QueryRow(sqlCommand, listId).Scan(&currentVersion)
+	if queryErr != nil {
+		return queryErr
+	}
+
+	if version != interfaces.ANY_VERSION && version != currentVersion {
+		return db.ErrVersionConflict
+	}
+
+	return nil
+}
+
+func PurgeList(ex db.Executor, listId string) error {
+	sqlCommand := "DELETE FROM lists WHERE id = $1;"
+
+	_, execErr := ex.Exec(sqlCommand, listId)
+
+	return execErr
+}
+
+func RetrieveListsByUserId(ownerId string, userId string, includeArchived bool) ([]interfaces.BasicListData, error) {
 	var listsBasicData []interfaces.BasicListData
 	var sqlCommand string
 
 	if ownerId == userId {
-		sqlCommand = "SELECT id, name, owner, private FROM lists WHERE owner = $1 OR members @> ARRAY[$1]::varchar[]"
+		sqlCommand = "SELECT id, name, owner, private, \"archivedAt\", version FROM lists WHERE \"deletedAt\" = -1 AND (owner = $1 OR members @> ARRAY[$1]::varchar[])"
 	} else {
-		sqlCommand = "SELECT id, name, owner, private FROM lists WHERE private = false AND owner = $1 OR members @> ARRAY[$1]::varchar[]"
+		sqlCommand = "SELECT id, name, owner, private, \"archivedAt\", version FROM lists WHERE \"deletedAt\" = -1 AND (private = false AND owner = $1 OR members @> ARRAY[$1]::varchar[])"
+	}
+
+	if !includeArchived {
+		sqlCommand += " AND \"archivedAt\" = -1"
 	}
 
 	rows, queryErr := db.Database.Query(sqlCommand, ownerId)
@@ -96,7 +202,7 @@ func RetrieveListsByUserId(ownerId string, userId string) ([]interfaces.BasicLis
 
 	for rows.Next() {
 		listBasicData := interfaces.BasicListData{}
-		scanErr := rows.Scan(&listBasicData.Id, &listBasicData.Name, &listBasicData.Owner, &listBasicData.Private)
+		scanErr := rows.Scan(&listBasicData.Id, &listBasicData.Name, &listBasicData.Owner, &listBasicData.Private, &listBasicData.ArchivedAt, &listBasicData.Version)
 		if scanErr != nil {
 			return listsBasicData, scanErr
 		}
@@ -134,7 +240,7 @@ func RetrieveMemberUsernamesFromMemberIds(memberIds []string) ([]string, error)
 func RetrieveOwnerIdByListId(listId string) (string, error) {
 	var ownerId string
 
-	sqlCommand := "SELECT owner FROM lists WHERE id = $1"
+	sqlCommand := "SELECT owner FROM lists WHERE id = $1 AND \"deletedAt\" = -1"
 
 	queryErr := db.Database.QueryRow(sqlCommand, listId).Scan(&ownerId)
 
@@ -148,15 +254,9 @@ func RetrieveOwnerIdByListId(listId string) (string, error) {
 func RetrieveListById(listId string) (interfaces.List, error) {
 	var list interfaces.List
 
-	sqlCommand := "SELECT * FROM lists WHERE id = $1"
+	sqlCommand := "SELECT " + LIST_COLUMNS + " FROM lists WHERE id = $1 AND \"deletedAt\" = -1"
 
-	queryErr := db.Database.QueryRow(sqlCommand, listId).Scan(
-		&list.Id,
-		&list.Name,
-		&list.Owner,
-		&list.Private,
-		pq.Array(&list.Members),
-	)
+	queryErr := db.Database.QueryRow(sqlCommand, listId).Scan(listFields(&list)...)
 
 	if queryErr != nil {
 		return list, queryErr
@@ -165,16 +265,70 @@ func RetrieveListById(listId string) (interfaces.List, error) {
 	return list, nil
 }
 
-func RetrieveNumberTasksInList(listId string) (int, error) {
-	var count int
+func RetrieveDeletedListById(listId string) (interfaces.List, error) {
+	var list interfaces.List
+
+	sqlCommand := "SELECT " + LIST_COLUMNS + " FROM lists WHERE id = $1 AND \"deletedAt\" <> -1"
+
+	queryErr := db.Database.QueryRow(sqlCommand, listId).Scan(listFields(&list)...)
+
+	return list, queryErr
+}
 
-	sqlCommand := "SELECT COUNT(*) FROM tasks WHERE \"listId\" = $1;"
+func RetrieveDeletedListsByUserId(userId string) ([]interfaces.List, error) {
+	var lists []interfaces.List
 
-	queryErr := db.Database.QueryRow(sqlCommand, listId).Scan(&count)
+	sqlCommand := "SELECT " + LIST_COLUMNS + " FROM lists WHERE \"deletedBy\" = $1 AND \"deletedAt\" <> -1 ORDER BY \"deletedAt\" DESC"
 
+	rows, queryErr := db.Database.Query(sqlCommand, userId)
 	if queryErr != nil {
-		return count, queryErr
+		return lists, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		list := interfaces.List{}
+		scanErr := rows.Scan(listFields(&list)...)
+		if scanErr != nil {
+			return lists, scanErr
+		}
+
+		lists = append(lists, list)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return lists, rowsErr
+	}
+
+	return lists, nil
+}
+
+func RetrieveExpiredListIds(deletedBefore int) ([]string, error) {
+	var listIds []string
+
+	sqlCommand := "SELECT id FROM lists WHERE \"deletedAt\" <> -1 AND \"deletedAt\" < $1"
+
+	rows, queryErr := db.Database.Query(sqlCommand, deletedBefore)
+	if queryErr != nil {
+		return listIds, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var listId string
+		scanErr := rows.Scan(&listId)
+		if scanErr != nil {
+			return listIds, scanErr
+		}
+
+		listIds = append(listIds, listId)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return listIds, rowsErr
 	}
 
-	return count, nil
+	return listIds, nil
 }
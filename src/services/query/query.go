@@ -0,0 +1,373 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tagService "github.com/beebeeoii/do-gether/services/tag"
+)
+
+// A query is a whitespace separated list of terms that must all match, e.g.
+//
+//	tag:work priority>=2 due<7d !completed list:"Home"
+//
+// Terms are either a field comparison (field, operator, value), a flag such as
+// completed, or a bare word searched for in the title and description. A
+// leading ! or - negates a term. Values may be double quoted to include
+// spaces.
+
+const (
+	OPERATOR_EQUAL         = ":"
+	OPERATOR_NOT_EQUAL     = "!="
+	OPERATOR_LESS          = "<"
+	OPERATOR_LESS_EQUAL    = "<="
+	OPERATOR_GREATER       = ">"
+	OPERATOR_GREATER_EQUAL = ">="
+
+	VALUE_NONE = "none"
+	VALUE_ME   = "me"
+
+	MAX_TERMS = 20
+)
+
+var timeFields = map[string]string{
+	"due":       "due",
+	"start":     "\"plannedStart\"",
+	"end":       "\"plannedEnd\"",
+	"created":   "\"createdAt\"",
+	"updated":   "\"updatedAt\"",
+	"completed": "\"completedAt\"",
+}
+
+var flagConditions = map[string]string{
+	"completed": "completed = true",
+	"archived":  "\"archivedAt\" <> -1",
+	"recurring": "recurrence <> ''",
+	"blocked":   "EXISTS (SELECT 1 FROM task_dependencies d JOIN tasks blocker ON blocker.id = d.\"blockedBy\" JOIN lists bl ON bl.id = blocker.\"listId\" WHERE d.\"taskId\" = tasks.id AND blocker.completed = false AND blocker.\"deletedAt\" = -1 AND bl.\"deletedAt\" = -1)",
+}
+
+type CompileOptions struct {
+	UserId     string
+	Now        time.Time // in the user's timezone, used for relative dates
+	FirstParam int       // index of the first placeholder to emit, e.g. 2 for $2
+}
+
+// Compiled is a query turned into a SQL condition over the tasks table.
+// Condition only refers to Args through placeholders.
+type Compiled struct {
+	Condition       string
+	Args            []interface{}
+	IncludeArchived bool // true if the query mentions archived itself
+}
+
+type term struct {
+	negated  bool
+	field    string
+	operator string
+	value    string
+}
+
+type compiler struct {
+	options CompileOptions
+	args    []interface{}
+}
+
+func Compile(query string, options CompileOptions) (Compiled, error) {
+	compiled := Compiled{Condition: "TRUE", Args: []interface{}{}}
+
+	terms, tokenizeErr := tokenize(query)
+	if tokenizeErr != nil {
+		return compiled, tokenizeErr
+	}
+
+	if len(terms) > MAX_TERMS {
+		return compiled, fmt.Errorf("invalid query: at most %d terms are allowed", MAX_TERMS)
+	}
+
+	c := compiler{options: options}
+	conditions := []string{}
+
+	for _, t := range terms {
+		condition, compileErr := c.compileTerm(t)
+		if compileErr != nil {
+			return compiled, compileErr
+		}
+
+		if t.negated {
+			condition = "NOT (" + condition + ")"
+		}
+		conditions = append(conditions, condition)
+
+		if t.field == "" && strings.ToLower(t.value) == "archived" {
+			compiled.IncludeArchived = true
+		}
+	}
+
+	if len(conditions) > 0 {
+		compiled.Condition = strings.Join(conditions, " AND ")
+	}
+	compiled.Args = c.args
+
+	return compiled, nil
+}
+
+func tokenize(query string) ([]term, error) {
+	terms := []term{}
+	runes := []rune(query)
+	i := 0
+
+	for i < len(runes) {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		t := term{}
+		if runes[i] == '!' || runes[i] == '-' {
+			t.negated = true
+			i++
+		}
+
+		start := i
+		for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+			i++
+		}
+		word := string(runes[start:i])
+
+		operator := readOperator(runes, i)
+		if operator != "" && word != "" {
+			t.field = strings.ToLower(word)
+			t.operator = operator
+			i += len(operator)
+			if operator == "=" {
+				t.operator = OPERATOR_EQUAL
+			}
+		} else {
+			i = start
+		}
+
+		value, next, valueErr := readValue(runes, i)
+		if valueErr != nil {
+			return terms, valueErr
+		}
+		i = next
+
+		if value == "" {
+			return terms, fmt.Errorf("invalid query: missing value near position %d", start)
+		}
+		t.value = value
+
+		terms = append(terms, t)
+	}
+
+	return terms, nil
+}
+
+func readOperator(runes []rune, i int) string {
+	for _, operator := range []string{OPERATOR_NOT_EQUAL, OPERATOR_LESS_EQUAL, OPERATOR_GREATER_EQUAL, OPERATOR_EQUAL, "=", OPERATOR_LESS, OPERATOR_GREATER} {
+		if strings.HasPrefix(string(runes[i:]), operator) {
+			return operator
+		}
+	}
+
+	return ""
+}
+
+func readValue(runes []rune, i int) (string, int, error) {
+	if i < len(runes) && runes[i] == '"' {
+		var value strings.Builder
+		i++
+
+		for i < len(runes) && runes[i] != '"' {
+			if runes[i] == '\\' && i+1 < len(runes) {
+				i++
+			}
+			value.WriteRune(runes[i])
+			i++
+		}
+
+		if i >= len(runes) {
+			return "", i, fmt.Errorf("invalid query: unterminated quote")
+		}
+
+		return value.String(), i + 1, nil
+	}
+
+	start := i
+	for i < len(runes) && !unicode.IsSpace(runes[i]) {
+		i++
+	}
+
+	return string(runes[start:i]), i, nil
+}
+
+func (c *compiler) param(value interface{}) string {
+	c.args = append(c.args, value)
+
+	return fmt.Sprintf("$%d", c.options.FirstParam+len(c.args)-1)
+}
+
+func (c *compiler) compileTerm(t term) (string, error) {
+	if t.field == "" {
+		if condition, ok := flagConditions[strings.ToLower(t.value)]; ok {
+			return condition, nil
+		}
+
+		return c.compileText(t.value), nil
+	}
+
+	if column, ok := timeFields[t.field]; ok {
+		return c.compileTime(column, t)
+	}
+
+	switch t.field {
+	case "priority":
+		return c.compilePriority(t)
+	case "tag":
+		if !isEquality(t.operator) {
+			return "", invalidOperatorErr(t)
+		}
+		// A parent tag also matches the tags nested under it.
+		condition := "EXISTS (SELECT 1 FROM unnest(tags) tag WHERE " + tagService.MatchCondition("tag", c.param(tagService.Normalise(t.value))) + ")"
+		return c.negateIfNotEqual(t, condition), nil
+	case "list":
+		if !isEquality(t.operator) {
+			return "", invalidOperatorErr(t)
+		}
+		return c.negateIfNotEqual(t, "\"listId\" IN (SELECT id FROM lists WHERE LOWER(name) = LOWER("+c.param(t.value)+"))"), nil
+	case "assignee":
+		if !isEquality(t.operator) {
+			return "", invalidOperatorErr(t)
+		}
+		if t.value == VALUE_NONE {
+			return c.negateIfNotEqual(t, "cardinality(assignees) = 0"), nil
+		}
+		if t.value == VALUE_ME {
+			return c.negateIfNotEqual(t, "assignees @> ARRAY["+c.param(c.options.UserId)+"]::varchar[]"), nil
+		}
+		return c.negateIfNotEqual(t, "assignees @> ARRAY[(SELECT id FROM users WHERE username = "+c.param(t.value)+")]::varchar[]"), nil
+	case "text":
+		if !isEquality(t.operator) {
+			return "", invalidOperatorErr(t)
+		}
+		return c.negateIfNotEqual(t, c.compileText(t.value)), nil
+	}
+
+	return "", fmt.Errorf("invalid query: unknown field %q", t.field)
+}
+
+func (c *compiler) compileText(value string) string {
+	pattern := "%" + strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value) + "%"
+	placeholder := c.param(pattern)
+
+	return "(title ILIKE " + placeholder + " OR description ILIKE " + placeholder + ")"
+}
+
+func (c *compiler) compilePriority(t term) (string, error) {
+	if t.value == VALUE_NONE {
+		if !isEquality(t.operator) {
+			return "", invalidOperatorErr(t)
+		}
+		return c.negateIfNotEqual(t, "priority = -1"), nil
+	}
+
+	priority, parseErr := strconv.Atoi(t.value)
+	if parseErr != nil {
+		return "", fmt.Errorf("invalid query: priority must be a number, got %q", t.value)
+	}
+
+	return "priority <> -1 AND priority " + sqlOperator(t.operator) + " " + c.param(priority), nil
+}
+
+func (c *compiler) compileTime(column string, t term) (string, error) {
+	if t.value == VALUE_NONE {
+		if !isEquality(t.operator) {
+			return "", invalidOperatorErr(t)
+		}
+		return c.negateIfNotEqual(t, column+" = -1"), nil
+	}
+
+	timestamp, parseErr := parseTime(t.value, c.options.Now)
+	if parseErr != nil {
+		return "", parseErr
+	}
+
+	// Equality matches anything within the day the value falls on, e.g.
+	// due:today or due:7d, since an exact second would hardly ever match.
+	if isEquality(t.operator) {
+		day := time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, timestamp.Location())
+		condition := column + " >= " + c.param(int(day.Unix())) + " AND " + column + " < " + c.param(int(day.AddDate(0, 0, 1).Unix()))
+		return c.negateIfNotEqual(t, condition), nil
+	}
+
+	return column + " <> -1 AND " + column + " " + sqlOperator(t.operator) + " " + c.param(int(timestamp.Unix())), nil
+}
+
+// parseTime understands today, tomorrow, yesterday, now, YYYY-MM-DD and
+// offsets from now such as 7d, -2w or 12h. Days start at midnight.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	location := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	switch strings.ToLower(value) {
+	case "now":
+		return now, nil
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	date, dateErr := time.ParseInLocation("2006-01-02", value, location)
+	if dateErr == nil {
+		return date, nil
+	}
+
+	if len(value) >= 2 {
+		amount, amountErr := strconv.Atoi(value[:len(value)-1])
+		if amountErr == nil {
+			switch value[len(value)-1] {
+			case 'h':
+				return now.Add(time.Duration(amount) * time.Hour), nil
+			case 'd':
+				return now.AddDate(0, 0, amount), nil
+			case 'w':
+				return now.AddDate(0, 0, 7*amount), nil
+			}
+		}
+	}
+
+	return now, fmt.Errorf("invalid query: cannot read %q as a date", value)
+}
+
+func (c *compiler) negateIfNotEqual(t term, condition string) string {
+	if t.operator == OPERATOR_NOT_EQUAL {
+		return "NOT (" + condition + ")"
+	}
+
+	return condition
+}
+
+func isEquality(operator string) bool {
+	return operator == OPERATOR_EQUAL || operator == OPERATOR_NOT_EQUAL
+}
+
+func sqlOperator(operator string) string {
+	switch operator {
+	case OPERATOR_EQUAL:
+		return "="
+	case OPERATOR_NOT_EQUAL:
+		return "<>"
+	}
+
+	return operator
+}
+
+func invalidOperatorErr(t term) error {
+	return fmt.Errorf("invalid query: %s does not support %s", t.field, t.operator)
+}
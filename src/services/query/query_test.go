@@ -0,0 +1,313 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestCompile(t *testing.T) {
+	singapore, loadErr := time.LoadLocation("Asia/Singapore")
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+
+	now := time.Date(2024, 3, 15, 10, 30, 0, 0, singapore)
+	day := func(year int, month time.Month, date int) int {
+		return int(time.Date(year, month, date, 0, 0, 0, 0, singapore).Unix())
+	}
+	tagCondition := func(placeholder string) string {
+		return "EXISTS (SELECT 1 FROM unnest(tags) tag WHERE (tag = " + placeholder + "::varchar OR left(tag, length(" + placeholder + "::varchar) + 1) = " + placeholder + "::varchar || '/'))"
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		firstParam      int
+		condition       string
+		args            []interface{}
+		includeArchived bool
+	}{
+		{
+			name:      "empty query",
+			query:     "  ",
+			condition: "TRUE",
+		},
+		{
+			name:      "flag",
+			query:     "completed",
+			condition: "completed = true",
+		},
+		{
+			name:      "negated flag",
+			query:     "!completed",
+			condition: "NOT (completed = true)",
+		},
+		{
+			name:      "flag is case insensitive",
+			query:     "Recurring",
+			condition: "recurrence <> ''",
+		},
+		{
+			name:            "archived flag includes archived tasks",
+			query:           "-archived",
+			condition:       "NOT (\"archivedAt\" <> -1)",
+			includeArchived: true,
+		},
+		{
+			name:      "bare word",
+			query:     "milk",
+			condition: "(title ILIKE $2 OR description ILIKE $2)",
+			args:      []interface{}{"%milk%"},
+		},
+		{
+			name:      "quoted words",
+			query:     `"oat milk"`,
+			condition: "(title ILIKE $2 OR description ILIKE $2)",
+			args:      []interface{}{"%oat milk%"},
+		},
+		{
+			name:      "negated word",
+			query:     "-milk",
+			condition: "NOT ((title ILIKE $2 OR description ILIKE $2))",
+			args:      []interface{}{"%milk%"},
+		},
+		{
+			name:      "bang followed by an equals sign is a negated word",
+			query:     "!=milk",
+			condition: "NOT ((title ILIKE $2 OR description ILIKE $2))",
+			args:      []interface{}{"%=milk%"},
+		},
+		{
+			name:      "text escapes like patterns",
+			query:     `text:"50% off_now\""`,
+			condition: "(title ILIKE $2 OR description ILIKE $2)",
+			args:      []interface{}{`%50\% off\_now"%`},
+		},
+		{
+			name:      "text not equal",
+			query:     "text!=milk",
+			condition: "NOT ((title ILIKE $2 OR description ILIKE $2))",
+			args:      []interface{}{"%milk%"},
+		},
+		{
+			name:      "tag",
+			query:     "tag:work",
+			condition: tagCondition("$2"),
+			args:      []interface{}{"work"},
+		},
+		{
+			name:      "tag with equals sign",
+			query:     "TAG=work",
+			condition: tagCondition("$2"),
+			args:      []interface{}{"work"},
+		},
+		{
+			name:      "tag not equal is normalised",
+			query:     `tag!=" work// clientA "`,
+			condition: "NOT (" + tagCondition("$2") + ")",
+			args:      []interface{}{"work/clientA"},
+		},
+		{
+			name:      "list",
+			query:     `list:"Home"`,
+			condition: "\"listId\" IN (SELECT id FROM lists WHERE LOWER(name) = LOWER($2))",
+			args:      []interface{}{"Home"},
+		},
+		{
+			name:      "list with escaped quotes",
+			query:     `list:"say \"hi\""`,
+			condition: "\"listId\" IN (SELECT id FROM lists WHERE LOWER(name) = LOWER($2))",
+			args:      []interface{}{`say "hi"`},
+		},
+		{
+			name:      "assignee me",
+			query:     "assignee:me",
+			condition: "assignees @> ARRAY[$2]::varchar[]",
+			args:      []interface{}{"user1"},
+		},
+		{
+			name:      "assignee none",
+			query:     "assignee:none",
+			condition: "cardinality(assignees) = 0",
+		},
+		{
+			name:      "assignee not equal",
+			query:     "assignee!=alice",
+			condition: "NOT (assignees @> ARRAY[(SELECT id FROM users WHERE username = $2)]::varchar[])",
+			args:      []interface{}{"alice"},
+		},
+		{
+			name:      "priority at least",
+			query:     "priority>=2",
+			condition: "priority <> -1 AND priority >= $2",
+			args:      []interface{}{2},
+		},
+		{
+			name:      "priority equal",
+			query:     "priority:3",
+			condition: "priority <> -1 AND priority = $2",
+			args:      []interface{}{3},
+		},
+		{
+			name:      "priority not equal",
+			query:     "priority!=1",
+			condition: "priority <> -1 AND priority <> $2",
+			args:      []interface{}{1},
+		},
+		{
+			name:      "priority none",
+			query:     "priority:none",
+			condition: "priority = -1",
+		},
+		{
+			name:      "due within days",
+			query:     "due<7d",
+			condition: "due <> -1 AND due < $2",
+			args:      []interface{}{int(now.AddDate(0, 0, 7).Unix())},
+		},
+		{
+			name:      "updated after negative weeks",
+			query:     "updated>-2w",
+			condition: "\"updatedAt\" <> -1 AND \"updatedAt\" > $2",
+			args:      []interface{}{int(now.AddDate(0, 0, -14).Unix())},
+		},
+		{
+			name:      "start within hours",
+			query:     "start<=12h",
+			condition: "\"plannedStart\" <> -1 AND \"plannedStart\" <= $2",
+			args:      []interface{}{int(now.Add(12 * time.Hour).Unix())},
+		},
+		{
+			name:      "end before now",
+			query:     "end<now",
+			condition: "\"plannedEnd\" <> -1 AND \"plannedEnd\" < $2",
+			args:      []interface{}{int(now.Unix())},
+		},
+		{
+			name:      "completed before yesterday",
+			query:     "completed<yesterday",
+			condition: "\"completedAt\" <> -1 AND \"completedAt\" < $2",
+			args:      []interface{}{day(2024, 3, 14)},
+		},
+		{
+			name:      "due today",
+			query:     "due:today",
+			condition: "due >= $2 AND due < $3",
+			args:      []interface{}{day(2024, 3, 15), day(2024, 3, 16)},
+		},
+		{
+			name:      "due not tomorrow",
+			query:     "due!=tomorrow",
+			condition: "NOT (due >= $2 AND due < $3)",
+			args:      []interface{}{day(2024, 3, 16), day(2024, 3, 17)},
+		},
+		{
+			name:      "due on a date",
+			query:     "created:2024-02-29",
+			condition: "\"createdAt\" >= $2 AND \"createdAt\" < $3",
+			args:      []interface{}{day(2024, 2, 29), day(2024, 3, 1)},
+		},
+		{
+			name:      "due on the day in a week",
+			query:     "due:7d",
+			condition: "due >= $2 AND due < $3",
+			args:      []interface{}{day(2024, 3, 22), day(2024, 3, 23)},
+		},
+		{
+			name:      "due on the day in some hours",
+			query:     "due:14h",
+			condition: "due >= $2 AND due < $3",
+			args:      []interface{}{day(2024, 3, 16), day(2024, 3, 17)},
+		},
+		{
+			name:      "due none",
+			query:     "due:none",
+			condition: "due = -1",
+		},
+		{
+			name:      "due not none",
+			query:     "due!=none",
+			condition: "NOT (due = -1)",
+		},
+		{
+			name:       "numbering follows the first param",
+			query:      `tag:work priority>=2 due<7d !completed list:"Home" milk`,
+			firstParam: 4,
+			condition:  tagCondition("$4") + " AND priority <> -1 AND priority >= $5 AND due <> -1 AND due < $6 AND NOT (completed = true) AND \"listId\" IN (SELECT id FROM lists WHERE LOWER(name) = LOWER($7)) AND (title ILIKE $8 OR description ILIKE $8)",
+			args:       []interface{}{"work", 2, int(now.AddDate(0, 0, 7).Unix()), "Home", "%milk%"},
+		},
+		{
+			name:       "numbering from one",
+			query:      "due:today milk",
+			firstParam: 1,
+			condition:  "due >= $1 AND due < $2 AND (title ILIKE $3 OR description ILIKE $3)",
+			args:       []interface{}{day(2024, 3, 15), day(2024, 3, 16), "%milk%"},
+		},
+		{
+			name:      "as many terms as allowed",
+			query:     strings.Repeat("completed ", MAX_TERMS),
+			condition: strings.TrimSuffix(strings.Repeat("completed = true AND ", MAX_TERMS), " AND "),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			firstParam := test.firstParam
+			if firstParam == 0 {
+				firstParam = 2
+			}
+
+			compiled, err := Compile(test.query, CompileOptions{UserId: "user1", Now: now, FirstParam: firstParam})
+			if err != nil {
+				t.Fatalf("Compile(%q) returned error %v", test.query, err)
+			}
+
+			if compiled.Condition != test.condition {
+				t.Errorf("condition = %s\nwant %s", compiled.Condition, test.condition)
+			}
+			if len(compiled.Args) != len(test.args) || (len(test.args) > 0 && !reflect.DeepEqual(compiled.Args, test.args)) {
+				t.Errorf("args = %#v, want %#v", compiled.Args, test.args)
+			}
+			if compiled.IncludeArchived != test.includeArchived {
+				t.Errorf("includeArchived = %v, want %v", compiled.IncludeArchived, test.includeArchived)
+			}
+		})
+	}
+}
+
+func TestCompileRejects(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"colour:red", `unknown field "colour"`},
+		{"tag<work", "tag does not support <"},
+		{"list>=Home", "list does not support >="},
+		{"assignee>me", "assignee does not support >"},
+		{"text<milk", "text does not support <"},
+		{"priority:high", `priority must be a number, got "high"`},
+		{"priority>none", "priority does not support >"},
+		{"due<someday", `cannot read "someday" as a date`},
+		{"due<7x", `cannot read "7x" as a date`},
+		{"due>=none", "due does not support >="},
+		{"created:2024-02-30", `cannot read "2024-02-30" as a date`},
+		{`list:"Home`, "unterminated quote"},
+		{"tag:", "missing value"},
+		{"!", "missing value"},
+		{strings.Repeat("milk ", MAX_TERMS+1), "at most 20 terms"},
+	}
+
+	for _, test := range tests {
+		_, err := Compile(test.query, CompileOptions{UserId: "user1", Now: time.Now(), FirstParam: 2})
+		if err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", test.query)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Compile(%q) returned error %q, want it to mention %q", test.query, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A rank is a string that orders tasks within a list: tasks are sorted by
+// comparing their ranks byte by byte. There is always room for another rank
+// between two different ranks, so moving a task only rewrites that task's
+// rank. Ranks only use DIGITS and never end in the smallest digit, which
+// would leave no room in front of them.
+
+const (
+	DIGITS = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+// Between returns a rank that sorts after before and ahead of after. An empty
+// before stands for the start of the list and an empty after for its end.
+func Between(before string, after string) (string, error) {
+	if !isValid(before) || !isValid(after) {
+		return "", fmt.Errorf("invalid rank")
+	}
+
+	if after != "" && before >= after {
+		return "", fmt.Errorf("no rank between %q and %q", before, after)
+	}
+
+	return midpoint(before, after), nil
+}
+
+// Spread returns n ranks evenly spread over the whole range, as short as they
+// can be.
+func Spread(n int) []string {
+	return spread("", "", n, make([]string, 0, n))
+}
+
+func spread(before string, after string, n int, ranks []string) []string {
+	if n <= 0 {
+		return ranks
+	}
+
+	middle := midpoint(before, after)
+	nBefore := (n - 1) / 2
+
+	ranks = spread(before, middle, nBefore, ranks)
+	ranks = append(ranks, middle)
+
+	return spread(middle, after, n-1-nBefore, ranks)
+}
+
+// midpoint treats ranks as the digits of a fraction between 0 and 1, with an
+// empty after standing for 1, and returns roughly the fraction halfway.
+func midpoint(before string, after string) string {
+	if after != "" {
+		n := 0
+		for n < len(after) && digitAt(before, n) == strings.IndexByte(DIGITS, after[n]) {
+			n++
+		}
+
+		if n > 0 {
+			return after[:n] + midpoint(suffix(before, n), after[n:])
+		}
+	}
+
+	digitBefore := digitAt(before, 0)
+	digitAfter := len(DIGITS)
+	if after != "" {
+		digitAfter = strings.IndexByte(DIGITS, after[0])
+	}
+
+	if digitAfter-digitBefore > 1 {
+		return string(DIGITS[(digitBefore+digitAfter+1)/2])
+	}
+
+	if len(after) > 1 {
+		return after[:1]
+	}
+
+	return string(DIGITS[digitBefore]) + midpoint(suffix(before, 1), "")
+}
+
+func digitAt(rank string, i int) int {
+	if i >= len(rank) {
+		return 0
+	}
+
+	return strings.IndexByte(DIGITS, rank[i])
+}
+
+func suffix(rank string, i int) string {
+	if i >= len(rank) {
+		return ""
+	}
+
+	return rank[i:]
+}
+
+func isValid(rank string) bool {
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(DIGITS, rank[i]) == -1 {
+			return false
+		}
+	}
+
+	return !strings.HasSuffix(rank, DIGITS[:1])
+}
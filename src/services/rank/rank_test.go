@@ -0,0 +1,218 @@
+package service
+
+import (
+	"math/bits"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{"empty list", "", ""},
+		{"start of list", "", "i"},
+		{"end of list", "i", ""},
+		{"spaced ranks", "a", "z"},
+		{"adjacent ranks", "a", "b"},
+		{"adjacent ranks with suffix", "a", "b1"},
+		{"prefix of after", "a", "a1"},
+		{"shared prefix", "abc", "abd"},
+		{"before longer than after", "a5", "b"},
+		{"after starting with the smallest digit", "", "01"},
+		{"last digit", "z", ""},
+		{"last digits", "zzz", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rank, err := Between(test.before, test.after)
+			if err != nil {
+				t.Fatalf("Between(%q, %q) returned error %v", test.before, test.after, err)
+			}
+
+			assertBetween(t, test.before, test.after, rank)
+		})
+	}
+}
+
+func TestBetweenRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+	}{
+		{"equal ranks", "a", "a"},
+		{"reversed ranks", "b", "a"},
+		{"invalid digit", "A", ""},
+		{"trailing smallest digit", "a0", ""},
+		{"invalid after", "", "a-"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rank, err := Between(test.before, test.after)
+			if err == nil {
+				t.Fatalf("Between(%q, %q) = %q, want an error", test.before, test.after, rank)
+			}
+		})
+	}
+}
+
+// Moving tasks to the same spot over and over makes ranks grow, until the
+// rebalancer of services/task respreads them once they pass MAX_RANK_LENGTH.
+// Every move halves the gap it lands in and every digit holds a little over
+// five halvings, so that takes around 80 moves.
+func TestBetweenGrowth(t *testing.T) {
+	const maxRankLength = 16
+
+	tests := []struct {
+		name     string
+		next     func(before string, after string, rank string) (string, string)
+		before   string
+		after    string
+		minMoves int
+	}{
+		{
+			name:     "always right after the first task",
+			next:     func(before string, after string, rank string) (string, string) { return before, rank },
+			before:   "i",
+			after:    "j",
+			minMoves: 70,
+		},
+		{
+			name:     "always right before the last task",
+			next:     func(before string, after string, rank string) (string, string) { return rank, after },
+			before:   "i",
+			after:    "j",
+			minMoves: 70,
+		},
+		{
+			name:     "always to the end of the list",
+			next:     func(before string, after string, rank string) (string, string) { return rank, after },
+			before:   "",
+			after:    "",
+			minMoves: 70,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, after := test.before, test.after
+			moves := 0
+
+			for {
+				rank, err := Between(before, after)
+				if err != nil {
+					t.Fatalf("move %d: Between(%q, %q) returned error %v", moves, before, after, err)
+				}
+				assertBetween(t, before, after, rank)
+
+				moves++
+				if len(rank) > maxRankLength {
+					break
+				}
+				if moves > 100000 {
+					t.Fatalf("ranks never grew past %d digits", maxRankLength)
+				}
+
+				before, after = test.next(before, after, rank)
+			}
+
+			if moves < test.minMoves {
+				t.Errorf("ranks grew past %d digits after %d moves, want at least %d", maxRankLength, moves, test.minMoves)
+			}
+		})
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 10, 35, 36, 100, 1000, 5000, 50000} {
+		ranks := Spread(n)
+		if len(ranks) != n {
+			t.Fatalf("Spread(%d) returned %d ranks", n, len(ranks))
+		}
+
+		if !sort.StringsAreSorted(ranks) {
+			t.Errorf("Spread(%d) is not sorted", n)
+		}
+
+		// Spreading bisects the range, and every digit holds about five
+		// levels of bisection.
+		maxLength := 1 + bits.Len(uint(n))/5
+
+		for i, rank := range ranks {
+			if !isValid(rank) || rank == "" {
+				t.Fatalf("Spread(%d)[%d] = %q is not a valid rank", n, i, rank)
+			}
+			if i > 0 && ranks[i-1] >= rank {
+				t.Fatalf("Spread(%d) repeats or misorders %q and %q", n, ranks[i-1], rank)
+			}
+			if len(rank) > maxLength {
+				t.Errorf("Spread(%d)[%d] = %q is longer than %d digits", n, i, rank, maxLength)
+			}
+		}
+	}
+}
+
+// Respread ranks must leave room on both ends and between neighbours, so the
+// next moves stay short.
+func TestSpreadLeavesRoom(t *testing.T) {
+	ranks := Spread(100)
+	bounds := append(append([]string{""}, ranks...), "")
+
+	for i := 0; i+1 < len(bounds); i++ {
+		rank, err := Between(bounds[i], bounds[i+1])
+		if err != nil {
+			t.Fatalf("Between(%q, %q) returned error %v", bounds[i], bounds[i+1], err)
+		}
+		if len(rank) > 3 {
+			t.Errorf("Between(%q, %q) = %q, want at most 3 digits", bounds[i], bounds[i+1], rank)
+		}
+	}
+}
+
+func TestMidpoint(t *testing.T) {
+	tests := []struct {
+		before string
+		after  string
+		want   string
+	}{
+		{"", "", "i"},
+		{"", "i", "9"},
+		{"i", "", "r"},
+		{"a", "c", "b"},
+		{"a", "b", "ai"},
+		{"z", "", "zi"},
+		{"a", "a1", "a0i"},
+		{"abc", "abd", "abci"},
+		{"", "1", "0i"},
+	}
+
+	for _, test := range tests {
+		got := midpoint(test.before, test.after)
+		if got != test.want {
+			t.Errorf("midpoint(%q, %q) = %q, want %q", test.before, test.after, got, test.want)
+		}
+	}
+}
+
+func assertBetween(t *testing.T, before string, after string, rank string) {
+	t.Helper()
+
+	if !isValid(rank) || rank == "" {
+		t.Fatalf("rank %q between %q and %q is not valid", rank, before, after)
+	}
+	if strings.HasSuffix(rank, DIGITS[:1]) {
+		t.Fatalf("rank %q ends in the smallest digit", rank)
+	}
+	if rank <= before {
+		t.Fatalf("rank %q does not sort after %q", rank, before)
+	}
+	if after != "" && rank >= after {
+		t.Fatalf("rank %q does not sort ahead of %q", rank, after)
+	}
+}
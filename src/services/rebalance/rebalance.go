@@ -0,0 +1,28 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+)
+
+const (
+	REBALANCE_INTERVAL = time.Hour
+)
+
+// Init starts the background job that keeps the task ranks of every list
+// short and distinct.
+func Init() {
+	go func() {
+		for {
+			rebalanceErr := taskService.RebalanceRanks(db.Database)
+			if rebalanceErr != nil {
+				log.Println(rebalanceErr)
+			}
+
+			time.Sleep(REBALANCE_INTERVAL)
+		}
+	}()
+}
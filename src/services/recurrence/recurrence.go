@@ -0,0 +1,381 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FREQ_DAILY   = "DAILY"
+	FREQ_WEEKLY  = "WEEKLY"
+	FREQ_MONTHLY = "MONTHLY"
+	FREQ_YEARLY  = "YEARLY"
+
+	RRULE_PREFIX    = "RRULE:"
+	UNTIL_LAYOUT    = "20060102T150405Z"
+	UNTIL_DATE_ONLY = "20060102"
+	MAX_ITERATIONS  = 1000
+)
+
+var presets = map[string]string{
+	"daily":   "FREQ=DAILY",
+	"weekly":  "FREQ=WEEKLY",
+	"monthly": "FREQ=MONTHLY",
+	"yearly":  "FREQ=YEARLY",
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+type weekdayNum struct {
+	Ordinal int // 0 for every occurrence in the period
+	Weekday time.Weekday
+}
+
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int   // 0 if unbounded
+	Until      int64 // -1 if unbounded
+	ByDay      []weekdayNum
+	ByMonthDay []int
+}
+
+// Normalise accepts either a preset ("daily", "weekly", "monthly", "yearly")
+// or an RFC 5545 RRULE and returns the canonical RRULE stored on a task.
+// An empty recurrence is returned as is.
+func Normalise(recurrence string) (string, error) {
+	recurrence = strings.TrimSpace(recurrence)
+	if recurrence == "" {
+		return "", nil
+	}
+
+	if preset, ok := presets[strings.ToLower(recurrence)]; ok {
+		recurrence = preset
+	}
+
+	rule, parseErr := Parse(recurrence)
+	if parseErr != nil {
+		return "", parseErr
+	}
+
+	return rule.String(), nil
+}
+
+func Parse(rrule string) (Rule, error) {
+	rule := Rule{
+		Interval: 1,
+		Until:    -1,
+	}
+
+	rrule = strings.TrimPrefix(strings.TrimSpace(rrule), RRULE_PREFIX)
+	if rrule == "" {
+		return rule, fmt.Errorf("empty recurrence rule")
+	}
+
+	for _, part := range strings.Split(rrule, ";") {
+		keyValue := strings.SplitN(part, "=", 2)
+		if len(keyValue) != 2 {
+			return rule, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		key := strings.ToUpper(keyValue[0])
+		value := strings.ToUpper(keyValue[1])
+
+		switch key {
+		case "FREQ":
+			if value != FREQ_DAILY && value != FREQ_WEEKLY && value != FREQ_MONTHLY && value != FREQ_YEARLY {
+				return rule, fmt.Errorf("unsupported recurrence frequency %q", value)
+			}
+			rule.Freq = value
+		case "INTERVAL":
+			interval, parseErr := strconv.Atoi(value)
+			if parseErr != nil || interval < 1 {
+				return rule, fmt.Errorf("invalid recurrence interval %q", value)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, parseErr := strconv.Atoi(value)
+			if parseErr != nil || count < 1 {
+				return rule, fmt.Errorf("invalid recurrence count %q", value)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, parseErr := time.Parse(UNTIL_LAYOUT, value)
+			if parseErr != nil {
+				until, parseErr = time.Parse(UNTIL_DATE_ONLY, value)
+			}
+			if parseErr != nil {
+				return rule, fmt.Errorf("invalid recurrence until %q", value)
+			}
+			rule.Until = until.Unix()
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				byDay, parseErr := parseWeekdayNum(day)
+				if parseErr != nil {
+					return rule, parseErr
+				}
+				rule.ByDay = append(rule.ByDay, byDay)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				monthDay, parseErr := strconv.Atoi(day)
+				if parseErr != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return rule, fmt.Errorf("invalid recurrence month day %q", day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, monthDay)
+			}
+		case "WKST":
+			if value != "MO" {
+				return rule, fmt.Errorf("unsupported recurrence week start %q", value)
+			}
+		default:
+			return rule, fmt.Errorf("unsupported recurrence rule part %q", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, fmt.Errorf("recurrence frequency is required")
+	}
+
+	if rule.Count > 0 && rule.Until != -1 {
+		return rule, fmt.Errorf("recurrence count and until cannot be used together")
+	}
+
+	if len(rule.ByMonthDay) > 0 && rule.Freq != FREQ_MONTHLY {
+		return rule, fmt.Errorf("recurrence month days are only supported for monthly frequency")
+	}
+
+	for _, byDay := range rule.ByDay {
+		if rule.Freq != FREQ_WEEKLY && rule.Freq != FREQ_MONTHLY {
+			return rule, fmt.Errorf("recurrence days are only supported for weekly and monthly frequency")
+		}
+		if byDay.Ordinal != 0 && rule.Freq != FREQ_MONTHLY {
+			return rule, fmt.Errorf("recurrence day ordinals are only supported for monthly frequency")
+		}
+	}
+
+	if len(rule.ByDay) > 0 && len(rule.ByMonthDay) > 0 {
+		return rule, fmt.Errorf("recurrence days and month days cannot be used together")
+	}
+
+	return rule, nil
+}
+
+func parseWeekdayNum(day string) (weekdayNum, error) {
+	if len(day) < 2 {
+		return weekdayNum{}, fmt.Errorf("invalid recurrence day %q", day)
+	}
+
+	weekday, ok := weekdays[day[len(day)-2:]]
+	if !ok {
+		return weekdayNum{}, fmt.Errorf("invalid recurrence day %q", day)
+	}
+
+	ordinal := 0
+	if len(day) > 2 {
+		parsedOrdinal, parseErr := strconv.Atoi(day[:len(day)-2])
+		if parseErr != nil || parsedOrdinal == 0 || parsedOrdinal < -5 || parsedOrdinal > 5 {
+			return weekdayNum{}, fmt.Errorf("invalid recurrence day %q", day)
+		}
+		ordinal = parsedOrdinal
+	}
+
+	return weekdayNum{Ordinal: ordinal, Weekday: weekday}, nil
+}
+
+func (rule Rule) String() string {
+	parts := []string{fmt.Sprintf("FREQ=%s", rule.Freq)}
+
+	if rule.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", rule.Interval))
+	}
+
+	if rule.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", rule.Count))
+	}
+
+	if rule.Until != -1 {
+		parts = append(parts, fmt.Sprintf("UNTIL=%s", time.Unix(rule.Until, 0).UTC().Format(UNTIL_LAYOUT)))
+	}
+
+	if len(rule.ByDay) > 0 {
+		days := []string{}
+		for _, byDay := range rule.ByDay {
+			day := strings.ToUpper(byDay.Weekday.String()[:2])
+			if byDay.Ordinal != 0 {
+				day = fmt.Sprintf("%d%s", byDay.Ordinal, day)
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, fmt.Sprintf("BYDAY=%s", strings.Join(days, ",")))
+	}
+
+	if len(rule.ByMonthDay) > 0 {
+		days := []string{}
+		for _, monthDay := range rule.ByMonthDay {
+			days = append(days, strconv.Itoa(monthDay))
+		}
+		parts = append(parts, fmt.Sprintf("BYMONTHDAY=%s", strings.Join(days, ",")))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the first occurrence strictly after the given occurrence and
+// the rule to store on that next occurrence, with COUNT consumed. It returns
+// false once the series has ended. The rule is expanded in the location of
+// occurrence, so days and times of day follow the user's calendar across
+// daylight saving changes.
+func (rule Rule) Next(occurrence time.Time) (time.Time, Rule, bool) {
+	if rule.Count == 1 {
+		return time.Time{}, rule, false
+	}
+
+	next, found := rule.nextCandidate(occurrence)
+	if !found {
+		return time.Time{}, rule, false
+	}
+
+	if rule.Until != -1 && next.Unix() > rule.Until {
+		return time.Time{}, rule, false
+	}
+
+	nextRule := rule
+	if rule.Count > 1 {
+		nextRule.Count = rule.Count - 1
+	}
+
+	return next, nextRule, true
+}
+
+func (rule Rule) nextCandidate(occurrence time.Time) (time.Time, bool) {
+	switch rule.Freq {
+	case FREQ_DAILY:
+		return occurrence.AddDate(0, 0, rule.Interval), true
+	case FREQ_WEEKLY:
+		return rule.nextWeekly(occurrence)
+	case FREQ_MONTHLY:
+		return rule.nextMonthly(occurrence)
+	case FREQ_YEARLY:
+		return rule.nextYearly(occurrence)
+	}
+
+	return time.Time{}, false
+}
+
+func (rule Rule) nextWeekly(occurrence time.Time) (time.Time, bool) {
+	if len(rule.ByDay) == 0 {
+		return occurrence.AddDate(0, 0, 7*rule.Interval), true
+	}
+
+	daysSinceMonday := (int(occurrence.Weekday()) + 6) % 7
+	weekStart := occurrence.AddDate(0, 0, -daysSinceMonday)
+
+	offsets := []int{}
+	for _, byDay := range rule.ByDay {
+		offsets = append(offsets, (int(byDay.Weekday)+6)%7)
+	}
+	sort.Ints(offsets)
+
+	for week := 0; week < MAX_ITERATIONS; week += rule.Interval {
+		for _, offset := range offsets {
+			candidate := weekStart.AddDate(0, 0, week*7+offset)
+			if candidate.After(occurrence) {
+				return candidate, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (rule Rule) nextMonthly(occurrence time.Time) (time.Time, bool) {
+	year, month, day := occurrence.Date()
+	hour, min, sec := occurrence.Clock()
+
+	for i := 0; i < MAX_ITERATIONS; i += rule.Interval {
+		monthStart := time.Date(year, month+time.Month(i), 1, hour, min, sec, 0, occurrence.Location())
+
+		candidates := rule.monthlyCandidates(monthStart, day)
+		for _, candidate := range candidates {
+			if candidate.After(occurrence) {
+				return candidate, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (rule Rule) monthlyCandidates(monthStart time.Time, anchorDay int) []time.Time {
+	daysInMonth := monthStart.AddDate(0, 1, -1).Day()
+	days := []int{}
+
+	switch {
+	case len(rule.ByMonthDay) > 0:
+		for _, monthDay := range rule.ByMonthDay {
+			if monthDay < 0 {
+				monthDay = daysInMonth + monthDay + 1
+			}
+			if monthDay >= 1 && monthDay <= daysInMonth {
+				days = append(days, monthDay)
+			}
+		}
+	case len(rule.ByDay) > 0:
+		for _, byDay := range rule.ByDay {
+			matches := []int{}
+			for monthDay := 1; monthDay <= daysInMonth; monthDay++ {
+				if monthStart.AddDate(0, 0, monthDay-1).Weekday() == byDay.Weekday {
+					matches = append(matches, monthDay)
+				}
+			}
+
+			switch {
+			case byDay.Ordinal == 0:
+				days = append(days, matches...)
+			case byDay.Ordinal > 0 && byDay.Ordinal <= len(matches):
+				days = append(days, matches[byDay.Ordinal-1])
+			case byDay.Ordinal < 0 && -byDay.Ordinal <= len(matches):
+				days = append(days, matches[len(matches)+byDay.Ordinal])
+			}
+		}
+	default:
+		if anchorDay <= daysInMonth {
+			days = append(days, anchorDay)
+		}
+	}
+
+	sort.Ints(days)
+
+	candidates := []time.Time{}
+	for _, monthDay := range days {
+		candidates = append(candidates, monthStart.AddDate(0, 0, monthDay-1))
+	}
+
+	return candidates
+}
+
+func (rule Rule) nextYearly(occurrence time.Time) (time.Time, bool) {
+	year, month, day := occurrence.Date()
+	hour, min, sec := occurrence.Clock()
+
+	for i := rule.Interval; i < MAX_ITERATIONS; i += rule.Interval {
+		candidate := time.Date(year+i, month, day, hour, min, sec, 0, occurrence.Location())
+		if candidate.Day() == day {
+			return candidate, true
+		}
+	}
+
+	return time.Time{}, false
+}
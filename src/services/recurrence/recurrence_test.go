@@ -0,0 +1,315 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		recurrence string
+		want       string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"daily", "FREQ=DAILY"},
+		{"Weekly", "FREQ=WEEKLY"},
+		{"monthly", "FREQ=MONTHLY"},
+		{"yearly", "FREQ=YEARLY"},
+		{"RRULE:FREQ=DAILY;INTERVAL=1", "FREQ=DAILY"},
+		{"freq=weekly;interval=2;byday=mo,we", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "FREQ=MONTHLY;BYDAY=-1FR"},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1", "FREQ=MONTHLY;BYMONTHDAY=1,-1"},
+		{"FREQ=DAILY;COUNT=3", "FREQ=DAILY;COUNT=3"},
+		{"FREQ=DAILY;UNTIL=20240131", "FREQ=DAILY;UNTIL=20240131T000000Z"},
+		{"FREQ=DAILY;UNTIL=20240131T120000Z", "FREQ=DAILY;UNTIL=20240131T120000Z"},
+		{"FREQ=WEEKLY;WKST=MO", "FREQ=WEEKLY"},
+	}
+
+	for _, test := range tests {
+		got, err := Normalise(test.recurrence)
+		if err != nil {
+			t.Errorf("Normalise(%q) returned error %v", test.recurrence, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Normalise(%q) = %q, want %q", test.recurrence, got, test.want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []string{
+		"RRULE:",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;INTERVAL=x",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240131",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=MO;BYMONTHDAY=1",
+		"FREQ=WEEKLY;WKST=SU",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;INTERVAL",
+	}
+
+	for _, rrule := range tests {
+		rule, err := Parse(rrule)
+		if err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", rrule, rule)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	newYork, loadErr := time.LoadLocation("America/New_York")
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+	singapore, loadErr := time.LoadLocation("Asia/Singapore")
+	if loadErr != nil {
+		t.Fatal(loadErr)
+	}
+
+	tests := []struct {
+		name       string
+		rrule      string
+		occurrence time.Time
+		want       []time.Time // the occurrences that follow, in order
+		wantRule   string      // rule stored on the last of them
+	}{
+		{
+			name:       "daily",
+			rrule:      "FREQ=DAILY",
+			occurrence: time.Date(2024, 1, 30, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=DAILY",
+		},
+		{
+			name:       "every other day",
+			rrule:      "FREQ=DAILY;INTERVAL=2",
+			occurrence: time.Date(2024, 2, 28, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=DAILY;INTERVAL=2",
+		},
+		{
+			name:       "weekly",
+			rrule:      "FREQ=WEEKLY",
+			occurrence: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=WEEKLY",
+		},
+		{
+			name:       "weekly on days",
+			rrule:      "FREQ=WEEKLY;BYDAY=MO,FR",
+			occurrence: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), // Wednesday
+			want: []time.Time{
+				time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 12, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=WEEKLY;BYDAY=MO,FR",
+		},
+		{
+			name:       "every other week on sunday",
+			rrule:      "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU",
+			occurrence: time.Date(2024, 1, 7, 9, 0, 0, 0, time.UTC), // Sunday
+			want: []time.Time{
+				time.Date(2024, 1, 21, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 2, 4, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU",
+		},
+		{
+			name:       "monthly",
+			rrule:      "FREQ=MONTHLY",
+			occurrence: time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 2, 15, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=MONTHLY",
+		},
+		{
+			name:       "monthly on the 31st skips short months",
+			rrule:      "FREQ=MONTHLY",
+			occurrence: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 5, 31, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=MONTHLY",
+		},
+		{
+			name:       "month day 31 skips short months",
+			rrule:      "FREQ=MONTHLY;BYMONTHDAY=31",
+			occurrence: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 5, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 7, 31, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=MONTHLY;BYMONTHDAY=31",
+		},
+		{
+			name:       "last day of the month",
+			rrule:      "FREQ=MONTHLY;BYMONTHDAY=-1",
+			occurrence: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 4, 30, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+		},
+		{
+			name:       "last friday of the month",
+			rrule:      "FREQ=MONTHLY;BYDAY=-1FR",
+			occurrence: time.Date(2024, 1, 26, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 2, 23, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 29, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=MONTHLY;BYDAY=-1FR",
+		},
+		{
+			name:       "yearly on a leap day",
+			rrule:      "FREQ=YEARLY",
+			occurrence: time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2028, 2, 29, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=YEARLY",
+		},
+		{
+			name:       "count is consumed",
+			rrule:      "FREQ=DAILY;COUNT=3",
+			occurrence: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=DAILY;COUNT=1",
+		},
+		{
+			name:       "until is inclusive",
+			rrule:      "FREQ=DAILY;UNTIL=20240103T090000Z",
+			occurrence: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+				time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC),
+			},
+			wantRule: "FREQ=DAILY;UNTIL=20240103T090000Z",
+		},
+		{
+			name:       "weekly keeps the time of day across daylight saving",
+			rrule:      "FREQ=WEEKLY;BYDAY=MO",
+			occurrence: time.Date(2024, 3, 4, 9, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2024, 3, 11, 9, 0, 0, 0, newYork), // clocks went forward on the 10th
+				time.Date(2024, 3, 18, 9, 0, 0, 0, newYork),
+			},
+			wantRule: "FREQ=WEEKLY;BYDAY=MO",
+		},
+		{
+			name:       "daily keeps the time of day when daylight saving ends",
+			rrule:      "FREQ=DAILY",
+			occurrence: time.Date(2024, 11, 2, 9, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2024, 11, 3, 9, 0, 0, 0, newYork),
+				time.Date(2024, 11, 4, 9, 0, 0, 0, newYork),
+			},
+			wantRule: "FREQ=DAILY",
+		},
+		{
+			name:       "days are those of the user's calendar",
+			rrule:      "FREQ=WEEKLY;BYDAY=MO",
+			occurrence: time.Date(2024, 1, 1, 7, 0, 0, 0, singapore), // Sunday 23:00 in UTC
+			want: []time.Time{
+				time.Date(2024, 1, 8, 7, 0, 0, 0, singapore),
+			},
+			wantRule: "FREQ=WEEKLY;BYDAY=MO",
+		},
+		{
+			name:       "month days are those of the user's calendar",
+			rrule:      "FREQ=MONTHLY;BYMONTHDAY=1",
+			occurrence: time.Date(2024, 1, 1, 7, 0, 0, 0, singapore),
+			want: []time.Time{
+				time.Date(2024, 2, 1, 7, 0, 0, 0, singapore),
+				time.Date(2024, 3, 1, 7, 0, 0, 0, singapore),
+			},
+			wantRule: "FREQ=MONTHLY;BYMONTHDAY=1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, parseErr := Parse(test.rrule)
+			if parseErr != nil {
+				t.Fatalf("Parse(%q) returned error %v", test.rrule, parseErr)
+			}
+
+			occurrence := test.occurrence
+			for i, want := range test.want {
+				next, nextRule, hasNext := rule.Next(occurrence)
+				if !hasNext {
+					t.Fatalf("occurrence %d: series ended, want %v", i, want)
+				}
+				if !next.Equal(want) {
+					t.Fatalf("occurrence %d: got %v, want %v", i, next, want)
+				}
+
+				occurrence, rule = next, nextRule
+			}
+
+			if rule.String() != test.wantRule {
+				t.Errorf("rule = %q, want %q", rule.String(), test.wantRule)
+			}
+		})
+	}
+}
+
+func TestNextEnds(t *testing.T) {
+	tests := []struct {
+		name       string
+		rrule      string
+		occurrence time.Time
+	}{
+		{"last of count", "FREQ=DAILY;COUNT=1", time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{"past until", "FREQ=DAILY;UNTIL=20240101T120000Z", time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{"until date", "FREQ=WEEKLY;UNTIL=20240107", time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+		{"no matching month day", "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=31", time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, parseErr := Parse(test.rrule)
+			if parseErr != nil {
+				t.Fatalf("Parse(%q) returned error %v", test.rrule, parseErr)
+			}
+
+			next, _, hasNext := rule.Next(test.occurrence)
+			if hasNext {
+				t.Errorf("got next occurrence %v, want the series to end", next)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"html"
+	"strings"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	"github.com/lib/pq"
+)
+
+// These expressions must match the GIN indexes created alongside the tasks,
+// comments and lists tables, otherwise the indexes are not used.
+const (
+	TASK_SEARCH_VECTOR    = "(setweight(to_tsvector('english', t.title), 'A') || setweight(to_tsvector('english', tags_to_text(t.tags)), 'A') || setweight(to_tsvector('english', t.description), 'B'))"
+	COMMENT_SEARCH_VECTOR = "to_tsvector('english', c.body)"
+	LIST_SEARCH_VECTOR    = "to_tsvector('english', l.name)"
+
+	// ts_headline marks matches with these control characters rather than
+	// with HTML, so that the text around them can be escaped before the
+	// marks are turned into <mark> tags. They are removed from the searched
+	// text first so that users cannot forge marks.
+	HIGHLIGHT_START = "\x02"
+	HIGHLIGHT_STOP  = "\x03"
+
+	HEADLINE_OPTIONS = "'StartSel=" + HIGHLIGHT_START + ", StopSel=" + HIGHLIGHT_STOP + ", MaxFragments=2, MaxWords=20, MinWords=5'"
+)
+
+var highlightReplacer = strings.NewReplacer(HIGHLIGHT_START, "<mark>", HIGHLIGHT_STOP, "</mark>")
+
+func withoutHighlights(column string) string {
+	return "translate(" + column + ", chr(2) || chr(3), '')"
+}
+
+func headline(column string) string {
+	return "ts_headline('english', " + withoutHighlights(column) + ", q.query, " + HEADLINE_OPTIONS + ")"
+}
+
+// highlight turns the output of headline into HTML that is safe to render.
+func highlight(text string) string {
+	return highlightReplacer.Replace(html.EscapeString(text))
+}
+
+// Search ranks the tasks, comments and list names within the given lists
+// against a web-style query such as `groceries -milk "oat bar"`.
+func Search(listIds []string, query string, limit int) ([]interfaces.SearchResult, error) {
+	results := []interfaces.SearchResult{}
+	sqlCommand := "WITH q AS (SELECT websearch_to_tsquery('english', $2) AS query) " +
+		"SELECT $3::text, t.id, t.\"listId\", t.id, " + headline("t.title") + ", " + headline("t.description") + ", ts_rank(" + TASK_SEARCH_VECTOR + ", q.query) AS rank " +
+		"FROM tasks t, q WHERE t.\"listId\" = ANY($1) AND t.\"deletedAt\" = -1 AND " + TASK_SEARCH_VECTOR + " @@ q.query " +
+		"UNION ALL " +
+		"SELECT $4::text, c.id, t.\"listId\", t.id, " + withoutHighlights("t.title") + ", " + headline("c.body") + ", ts_rank(" + COMMENT_SEARCH_VECTOR + ", q.query) AS rank " +
+		"FROM comments c JOIN tasks t ON t.id = c.\"taskId\", q WHERE t.\"listId\" = ANY($1) AND t.\"deletedAt\" = -1 AND " + COMMENT_SEARCH_VECTOR + " @@ q.query " +
+		"UNION ALL " +
+		"SELECT $5::text, l.id, l.id, '', " + headline("l.name") + ", '', ts_rank(" + LIST_SEARCH_VECTOR + ", q.query) AS rank " +
+		"FROM lists l, q WHERE l.id = ANY($1) AND " + LIST_SEARCH_VECTOR + " @@ q.query " +
+		"ORDER BY rank DESC LIMIT $6"
+
+	rows, queryErr := db.Database.Query(
+		sqlCommand,
+		pq.Array(listIds),
+		query,
+		interfaces.SEARCH_RESULT_TASK,
+		interfaces.SEARCH_RESULT_COMMENT,
+		interfaces.SEARCH_RESULT_LIST,
+		limit,
+	)
+	if queryErr != nil {
+		return results, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result := interfaces.SearchResult{}
+		scanErr := rows.Scan(
+			&result.Type,
+			&result.Id,
+			&result.ListId,
+			&result.TaskId,
+			&result.Title,
+			&result.Snippet,
+			&result.Rank,
+		)
+		if scanErr != nil {
+			return results, scanErr
+		}
+
+		result.Title = highlight(result.Title)
+		result.Snippet = highlight(result.Snippet)
+		results = append(results, result)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return results, rowsErr
+	}
+
+	return results, nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"buy oat milk", "buy oat milk"},
+		{"buy \x02oat\x03 milk", "buy <mark>oat</mark> milk"},
+		{"\x02oat\x03 and \x02oats\x03", "<mark>oat</mark> and <mark>oats</mark>"},
+		{"<script>alert(1)</script> \x02oat\x03", "&lt;script&gt;alert(1)&lt;/script&gt; <mark>oat</mark>"},
+		{"<img src=x onerror=\"alert('x')\">", "&lt;img src=x onerror=&#34;alert(&#39;x&#39;)&#34;&gt;"},
+		{"<mark>fake</mark> & \x02real\x03", "&lt;mark&gt;fake&lt;/mark&gt; &amp; <mark>real</mark>"},
+	}
+
+	for _, test := range tests {
+		got := highlight(test.text)
+		if got != test.want {
+			t.Errorf("highlight(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
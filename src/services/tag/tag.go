@@ -0,0 +1,128 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+)
+
+// Tags form a hierarchy by separating their levels with slashes, e.g.
+// work/clientA/billing is nested under work/clientA, which is nested under
+// work. A parent tag stands for itself and all of its descendants.
+
+const (
+	SEPARATOR = "/"
+)
+
+// Normalise trims the whitespace around every level of a tag and drops empty
+// levels, so " work//clientA/ " becomes "work/clientA".
+func Normalise(tag string) string {
+	levels := []string{}
+
+	for _, level := range strings.Split(tag, SEPARATOR) {
+		level = strings.TrimSpace(level)
+		if level != "" {
+			levels = append(levels, level)
+		}
+	}
+
+	return strings.Join(levels, SEPARATOR)
+}
+
+func NormaliseAll(tags []string) []string {
+	normalised := []string{}
+
+	for _, tag := range tags {
+		normalised = append(normalised, Normalise(tag))
+	}
+
+	return normalised
+}
+
+// IsWithin tells whether tag is ancestor itself or one of its descendants.
+func IsWithin(tag string, ancestor string) bool {
+	return tag == ancestor || strings.HasPrefix(tag, ancestor+SEPARATOR)
+}
+
+// Rewrite moves a tag that is within one of the sources to the same place
+// under target, using the most specific source that matches.
+func Rewrite(tag string, sources []string, target string) (string, bool) {
+	matched := ""
+	found := false
+
+	for _, source := range sources {
+		if IsWithin(tag, source) && (!found || len(source) > len(matched)) {
+			matched = source
+			found = true
+		}
+	}
+
+	if !found {
+		return tag, false
+	}
+
+	return target + tag[len(matched):], true
+}
+
+// MatchCondition is the SQL counterpart of IsWithin. Both arguments are SQL
+// expressions, e.g. a column and a placeholder.
+func MatchCondition(tag string, ancestor string) string {
+	ancestor += "::varchar"
+
+	return "(" + tag + " = " + ancestor + " OR left(" + tag + ", length(" + ancestor + ") + 1) = " + ancestor + " || '" + SEPARATOR + "')"
+}
+
+// BuildTree nests tags under their parents, creating the parents that are
+// missing, and sorts every level by name.
+func BuildTree(tags []interfaces.Tag) []interfaces.TagNode {
+	root := &interfaces.TagNode{Children: []interfaces.TagNode{}}
+
+	for _, tag := range tags {
+		node := root
+
+		for _, level := range strings.Split(tag.Name, SEPARATOR) {
+			node = childNode(node, level)
+		}
+
+		node.Color = tag.Color
+		node.Description = tag.Description
+		node.Usage = tag.Usage
+		node.DirectUsage = tag.DirectUsage
+	}
+
+	sortTree(root.Children)
+
+	return root.Children
+}
+
+func childNode(parent *interfaces.TagNode, name string) *interfaces.TagNode {
+	for i := range parent.Children {
+		if parent.Children[i].Name == name {
+			return &parent.Children[i]
+		}
+	}
+
+	path := name
+	if parent.Path != "" {
+		path = parent.Path + SEPARATOR + name
+	}
+
+	parent.Children = append(parent.Children, interfaces.TagNode{
+		Name:     name,
+		Path:     path,
+		Children: []interfaces.TagNode{},
+	})
+
+	return &parent.Children[len(parent.Children)-1]
+}
+
+func sortTree(nodes []interfaces.TagNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	for i := range nodes {
+		sortTree(nodes[i].Children)
+	}
+}
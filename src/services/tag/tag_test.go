@@ -0,0 +1,161 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/beebeeoii/do-gether/interfaces"
+)
+
+func TestNormalise(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"work", "work"},
+		{" work ", "work"},
+		{"work/clientA", "work/clientA"},
+		{" work//clientA/ ", "work/clientA"},
+		{"/work/ client A /billing/", "work/client A/billing"},
+		{"Work", "Work"},
+		{"//", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		got := Normalise(test.tag)
+		if got != test.want {
+			t.Errorf("Normalise(%q) = %q, want %q", test.tag, got, test.want)
+		}
+	}
+}
+
+func TestNormaliseAll(t *testing.T) {
+	got := NormaliseAll([]string{" a ", "b//c"})
+	want := []string{"a", "b/c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NormaliseAll = %q, want %q", got, want)
+	}
+
+	if got := NormaliseAll(nil); got == nil || len(got) != 0 {
+		t.Errorf("NormaliseAll(nil) = %#v, want an empty slice", got)
+	}
+}
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		tag      string
+		ancestor string
+		want     bool
+	}{
+		{"work", "work", true},
+		{"work/clientA", "work", true},
+		{"work/clientA/billing", "work", true},
+		{"work/clientA/billing", "work/clientA", true},
+		{"workshop", "work", false},
+		{"work", "work/clientA", false},
+		{"home/work", "work", false},
+		{"work/clientAB", "work/clientA", false},
+		{"Work", "work", false},
+	}
+
+	for _, test := range tests {
+		got := IsWithin(test.tag, test.ancestor)
+		if got != test.want {
+			t.Errorf("IsWithin(%q, %q) = %v, want %v", test.tag, test.ancestor, got, test.want)
+		}
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		tag       string
+		sources   []string
+		target    string
+		want      string
+		rewritten bool
+	}{
+		{"rename", "a", []string{"a"}, "c", "c", true},
+		{"rename keeps descendants", "a/b", []string{"a"}, "c", "c/b", true},
+		{"rename a nested tag", "a/b/d", []string{"a/b"}, "c", "c/d", true},
+		{"move under another tag", "a/b", []string{"a"}, "x/y", "x/y/b", true},
+		{"unrelated tag", "b/a", []string{"a"}, "c", "b/a", false},
+		{"shared prefix is not a parent", "ab", []string{"a"}, "c", "ab", false},
+		{"merge", "b/x", []string{"a", "b"}, "c", "c/x", true},
+		{"most specific source wins", "a/b/x", []string{"a", "a/b"}, "c", "c/x", true},
+		{"most specific source wins in any order", "a/b/x", []string{"a/b", "a"}, "c", "c/x", true},
+		{"no sources", "a", []string{}, "c", "a", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, rewritten := Rewrite(test.tag, test.sources, test.target)
+			if got != test.want || rewritten != test.rewritten {
+				t.Errorf("Rewrite(%q, %q, %q) = %q, %v, want %q, %v", test.tag, test.sources, test.target, got, rewritten, test.want, test.rewritten)
+			}
+		})
+	}
+}
+
+func TestMatchCondition(t *testing.T) {
+	got := MatchCondition("tag", "$2")
+	want := "(tag = $2::varchar OR left(tag, length($2::varchar) + 1) = $2::varchar || '/')"
+	if got != want {
+		t.Errorf("MatchCondition = %s, want %s", got, want)
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	tree := BuildTree([]interfaces.Tag{
+		{Name: "work/clientB", Color: "#0000ff", Usage: 1, DirectUsage: 1},
+		{Name: "work/clientA/billing", Usage: 2, DirectUsage: 2},
+		{Name: "home", Description: "Chores", Usage: 3, DirectUsage: 3},
+		{Name: "work", Color: "#ff0000", Usage: 5, DirectUsage: 2},
+	})
+
+	want := []interfaces.TagNode{
+		{
+			Name:        "home",
+			Path:        "home",
+			Description: "Chores",
+			Usage:       3,
+			DirectUsage: 3,
+			Children:    []interfaces.TagNode{},
+		},
+		{
+			Name:        "work",
+			Path:        "work",
+			Color:       "#ff0000",
+			Usage:       5,
+			DirectUsage: 2,
+			Children: []interfaces.TagNode{
+				{
+					Name: "clientA",
+					Path: "work/clientA",
+					Children: []interfaces.TagNode{
+						{
+							Name:        "billing",
+							Path:        "work/clientA/billing",
+							Usage:       2,
+							DirectUsage: 2,
+							Children:    []interfaces.TagNode{},
+						},
+					},
+				},
+				{
+					Name:        "clientB",
+					Path:        "work/clientB",
+					Color:       "#0000ff",
+					Usage:       1,
+					DirectUsage: 1,
+					Children:    []interfaces.TagNode{},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(tree, want) {
+		t.Errorf("BuildTree = %+v\nwant %+v", tree, want)
+	}
+}
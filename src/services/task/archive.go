@@ -0,0 +1,123 @@
+package service
+
+import (
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	historyService "github.com/beebeeoii/do-gether/services/history"
+)
+
+const SECONDS_PER_DAY = 24 * 60 * 60
+
+// ArchiveTask archives or unarchives a task. Archived tasks drop out of the
+// list order and are appended to the end of the list when unarchived.
+func ArchiveTask(ex db.Executor, actorId string, taskId string, archived bool, version int) (interfaces.Task, error) {
+	var updatedTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockTaskAtVersion(tx, taskId, version)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		previousTask, retrieveErr := retrieveTaskById(tx, taskId)
+		if retrieveErr != nil {
+			return retrieveErr
+		}
+
+		if (previousTask.ArchivedAt != -1) == archived {
+			updatedTask = previousTask
+			return nil
+		}
+
+		if archived {
+			sqlCommand := "UPDATE tasks SET version = version + 1, \"archivedAt\" = $1 WHERE id = $2 RETURNING " + TASK_COLUMNS + ";"
+
+			queryErr := queryTask(tx, &updatedTask, sqlCommand, int(time.Now().Unix()), taskId)
+			if queryErr != nil {
+				return queryErr
+			}
+
+		} else {
+			rank, rankErr := rankAfterList(tx, previousTask.ListId)
+			if rankErr != nil {
+				return rankErr
+			}
+
+			sqlCommand := "UPDATE tasks SET version = version + 1, \"archivedAt\" = -1, rank = $1 WHERE id = $2 RETURNING " + TASK_COLUMNS + ";"
+
+			queryErr := queryTask(tx, &updatedTask, sqlCommand, rank, taskId)
+			if queryErr != nil {
+				return queryErr
+			}
+		}
+
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_ARCHIVE, &previousTask, &updatedTask)
+	})
+
+	return updatedTask, transactErr
+}
+
+// ArchiveStaleCompletedTasks archives the tasks that were completed longer
+// ago than the autoArchiveDays setting of their list.
+func ArchiveStaleCompletedTasks(ex db.Executor) ([]interfaces.Task, error) {
+	var archivedTasks []interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		var archiveErr error
+		archivedTasks, archiveErr = archiveStaleCompletedTasks(tx)
+
+		return archiveErr
+	})
+
+	return archivedTasks, transactErr
+}
+
+func archiveStaleCompletedTasks(tx db.Executor) ([]interfaces.Task, error) {
+	var archivedTasks []interfaces.Task
+	now := int(time.Now().Unix())
+	sqlCommand := "UPDATE tasks SET version = version + 1, \"archivedAt\" = $1 WHERE id IN (" +
+		"SELECT t.id FROM tasks t JOIN lists l ON l.id = t.\"listId\" " +
+		"WHERE l.\"autoArchiveDays\" <> -1 AND l.\"deletedAt\" = -1 AND t.completed = true AND t.\"deletedAt\" = -1 AND t.\"archivedAt\" = -1 " +
+		"AND t.\"completedAt\" <> -1 AND t.\"completedAt\" < $1 - l.\"autoArchiveDays\" * $2" +
+		") RETURNING " + TASK_COLUMNS + ";"
+
+	rows, queryErr := tx.Query(sqlCommand, now, SECONDS_PER_DAY)
+	if queryErr != nil {
+		return archivedTasks, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task := interfaces.Task{}
+		scanErr := rows.Scan(taskFields(&task)...)
+		if scanErr != nil {
+			return archivedTasks, scanErr
+		}
+
+		archivedTasks = append(archivedTasks, task)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return archivedTasks, rowsErr
+	}
+
+	listOrdersErr := attachListOrders(tx, archivedTasks)
+	if listOrdersErr != nil {
+		return archivedTasks, listOrdersErr
+	}
+
+	for index := range archivedTasks {
+		previousTask := archivedTasks[index]
+		previousTask.ArchivedAt = -1
+
+		recordErr := historyService.RecordTaskEvent(tx, interfaces.TASK_EVENT_SYSTEM_ACTOR, interfaces.TASK_EVENT_ARCHIVE, &previousTask, &archivedTasks[index])
+		if recordErr != nil {
+			return archivedTasks, recordErr
+		}
+	}
+
+	return archivedTasks, nil
+}
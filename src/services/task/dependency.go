@@ -0,0 +1,175 @@
+package service
+
+import (
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	"github.com/lib/pq"
+)
+
+// CreateTaskDependency marks a task as blocked by another. The dependent task
+// is locked first, so that a completion checking its blockers either runs
+// before the dependency is added or sees it.
+func CreateTaskDependency(ex db.Executor, dependency interfaces.TaskDependency) (interfaces.TaskDependency, error) {
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockTaskAtVersion(tx, dependency.TaskId, interfaces.ANY_VERSION)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		sqlCommand := "INSERT INTO task_dependencies (\"taskId\", \"blockedBy\") VALUES ($1, $2) ON CONFLICT DO NOTHING;"
+
+		_, execErr := tx.Exec(sqlCommand, dependency.TaskId, dependency.BlockedBy)
+
+		return execErr
+	})
+
+	return dependency, transactErr
+}
+
+func DeleteTaskDependency(ex db.Executor, dependency interfaces.TaskDependency) (interfaces.TaskDependency, error) {
+	var deletedDependency interfaces.TaskDependency
+	sqlCommand := "DELETE FROM task_dependencies WHERE \"taskId\" = $1 AND \"blockedBy\" = $2 RETURNING \"taskId\", \"blockedBy\";"
+
+	queryErr := ex.QueryRow(
+		sqlCommand,
+		dependency.TaskId,
+		dependency.BlockedBy,
+	).Scan(
+		&deletedDependency.TaskId,
+		&deletedDependency.BlockedBy,
+	)
+
+	return deletedDependency, queryErr
+}
+
+func DeleteDependenciesOfTask(ex db.Executor, taskId string) error {
+	sqlCommand := "DELETE FROM task_dependencies WHERE \"taskId\" = $1 OR \"blockedBy\" = $1;"
+
+	_, execErr := ex.Exec(sqlCommand, taskId)
+	if execErr != nil {
+		return execErr
+	}
+
+	return nil
+}
+
+func DeleteDependenciesFromList(ex db.Executor, listId string) error {
+	sqlCommand := "DELETE FROM task_dependencies WHERE \"taskId\" IN (SELECT id FROM tasks WHERE \"listId\" = $1) OR \"blockedBy\" IN (SELECT id FROM tasks WHERE \"listId\" = $1);"
+
+	_, execErr := ex.Exec(sqlCommand, listId)
+	if execErr != nil {
+		return execErr
+	}
+
+	return nil
+}
+
+// LockTaskDependencies makes the other transactions changing dependencies
+// wait until tx is done, so that a cycle check made in tx stays true until
+// the dependency is added.
+func LockTaskDependencies(tx db.Executor) error {
+	sqlCommand := "LOCK TABLE task_dependencies IN SHARE ROW EXCLUSIVE MODE;"
+
+	_, execErr := tx.Exec(sqlCommand)
+
+	return execErr
+}
+
+// WouldCreateDependencyCycle reports whether marking taskId as blocked by
+// blockedBy would close a loop, i.e. whether taskId already (transitively)
+// blocks blockedBy.
+func WouldCreateDependencyCycle(ex db.Executor, taskId string, blockedBy string) (bool, error) {
+	if taskId == blockedBy {
+		return true, nil
+	}
+
+	var hasCycle bool
+	sqlCommand := `WITH RECURSIVE blockers ("blockedBy") AS (
+		SELECT "blockedBy" FROM task_dependencies WHERE "taskId" = $1
+		UNION
+		SELECT d."blockedBy" FROM task_dependencies d JOIN blockers b ON d."taskId" = b."blockedBy"
+	) SELECT EXISTS (SELECT 1 FROM blockers WHERE "blockedBy" = $2);`
+
+	queryErr := ex.QueryRow(sqlCommand, blockedBy, taskId).Scan(&hasCycle)
+
+	return hasCycle, queryErr
+}
+
+// RetrieveOpenBlockerIds returns the blockers of a task that are still open.
+// Blockers in the trash, or in a list in the trash, no longer block it.
+func RetrieveOpenBlockerIds(ex db.Executor, taskId string) ([]string, error) {
+	var blockerIds []string
+	sqlCommand := "SELECT d.\"blockedBy\" FROM task_dependencies d JOIN tasks t ON t.id = d.\"blockedBy\" JOIN lists l ON l.id = t.\"listId\" WHERE d.\"taskId\" = $1 AND t.completed = false AND t.\"deletedAt\" = -1 AND l.\"deletedAt\" = -1"
+
+	rows, queryErr := ex.Query(sqlCommand, taskId)
+	if queryErr != nil {
+		return blockerIds, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var blockerId string
+		scanErr := rows.Scan(&blockerId)
+		if scanErr != nil {
+			return blockerIds, scanErr
+		}
+
+		blockerIds = append(blockerIds, blockerId)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return blockerIds, rowsErr
+	}
+
+	return blockerIds, nil
+}
+
+// attachDependencies fills in BlockedBy, Blocking and Blocked on the given
+// tasks with a single query.
+func attachDependencies(tasks []interfaces.Task) error {
+	taskIds := []string{}
+	tasksById := make(map[string]*interfaces.Task)
+
+	for index := range tasks {
+		tasks[index].BlockedBy = []string{}
+		tasks[index].Blocking = []string{}
+		taskIds = append(taskIds, tasks[index].Id)
+		tasksById[tasks[index].Id] = &tasks[index]
+	}
+
+	if len(taskIds) == 0 {
+		return nil
+	}
+
+	sqlCommand := "SELECT d.\"taskId\", d.\"blockedBy\", t.completed FROM task_dependencies d JOIN tasks t ON t.id = d.\"blockedBy\" JOIN tasks dependent ON dependent.id = d.\"taskId\" JOIN lists l ON l.id = t.\"listId\" JOIN lists dl ON dl.id = dependent.\"listId\" WHERE (d.\"taskId\" = ANY($1) OR d.\"blockedBy\" = ANY($1)) AND t.\"deletedAt\" = -1 AND dependent.\"deletedAt\" = -1 AND l.\"deletedAt\" = -1 AND dl.\"deletedAt\" = -1"
+
+	rows, queryErr := db.Database.Query(sqlCommand, pq.Array(taskIds))
+	if queryErr != nil {
+		return queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskId, blockedBy string
+		var blockerCompleted bool
+
+		scanErr := rows.Scan(&taskId, &blockedBy, &blockerCompleted)
+		if scanErr != nil {
+			return scanErr
+		}
+
+		if task, ok := tasksById[taskId]; ok {
+			task.BlockedBy = append(task.BlockedBy, blockedBy)
+			if !blockerCompleted {
+				task.Blocked = true
+			}
+		}
+
+		if task, ok := tasksById[blockedBy]; ok {
+			task.Blocking = append(task.Blocking, taskId)
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	historyService "github.com/beebeeoii/do-gether/services/history"
+	rankService "github.com/beebeeoii/do-gether/services/rank"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+	"github.com/lib/pq"
+)
+
+// Tasks are ordered within their list by rank, see services/rank. listOrder is
+// derived from it by attachListOrders after reading: the number of shown tasks
+// ranked ahead of the task, or -1 for tasks in the trash or the archive.
+
+const (
+	MAX_RANK_LENGTH = 16 // ranks longer than this get respread by RebalanceRanks
+)
+
+// attachListOrders fills in ListOrder on the given tasks with a single query
+// that numbers the shown tasks of their lists in rank order.
+func attachListOrders(ex db.Executor, tasks []interfaces.Task) error {
+	listIds := []string{}
+	taskIds := []string{}
+	tasksById := make(map[string]*interfaces.Task)
+
+	for index := range tasks {
+		tasks[index].ListOrder = -1
+		if tasks[index].DeletedAt != -1 || tasks[index].ArchivedAt != -1 {
+			continue
+		}
+
+		if !utils.Contains(listIds, tasks[index].ListId) {
+			listIds = append(listIds, tasks[index].ListId)
+		}
+		taskIds = append(taskIds, tasks[index].Id)
+		tasksById[tasks[index].Id] = &tasks[index]
+	}
+
+	if len(taskIds) == 0 {
+		return nil
+	}
+
+	sqlCommand := "SELECT id, \"listOrder\" FROM (SELECT id, row_number() OVER (PARTITION BY \"listId\" ORDER BY rank, id) - 1 AS \"listOrder\" FROM tasks WHERE \"listId\" = ANY($1) AND \"deletedAt\" = -1 AND \"archivedAt\" = -1) shown WHERE id = ANY($2)"
+
+	rows, queryErr := ex.Query(sqlCommand, pq.Array(listIds), pq.Array(taskIds))
+	if queryErr != nil {
+		return queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskId string
+		var listOrder int
+
+		scanErr := rows.Scan(&taskId, &listOrder)
+		if scanErr != nil {
+			return scanErr
+		}
+
+		tasksById[taskId].ListOrder = listOrder
+	}
+
+	return rows.Err()
+}
+
+// queryTask scans the single task in TASK_COLUMNS returned by sqlCommand into
+// task, together with its listOrder.
+func queryTask(ex db.Executor, task *interfaces.Task, sqlCommand string, args ...interface{}) error {
+	queryErr := ex.QueryRow(sqlCommand, args...).Scan(taskFields(task)...)
+	if queryErr != nil {
+		return queryErr
+	}
+
+	tasks := []interfaces.Task{*task}
+	attachErr := attachListOrders(ex, tasks)
+	*task = tasks[0]
+
+	return attachErr
+}
+
+// ReorderTask moves a task to newListOrder among the shown tasks of its list
+// by giving it a rank between its new neighbours, leaving every other task
+// untouched. The list stays locked meanwhile, so moves made at the same time
+// in the same list are applied one after the other.
+func ReorderTask(ex db.Executor, actorId string, taskId string, listId string, newListOrder int) ([]interfaces.Task, error) {
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockList(tx, listId)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		previousTask, retrieveErr := retrieveTaskById(tx, taskId)
+		if retrieveErr != nil {
+			return retrieveErr
+		}
+
+		if previousTask.ListId != listId || previousTask.ArchivedAt != -1 {
+			return fmt.Errorf("task not found in list")
+		}
+
+		rank, moved, rankErr := rankAtListOrder(tx, taskId, listId, newListOrder)
+		if rankErr != nil {
+			return rankErr
+		}
+
+		if !moved {
+			return nil
+		}
+
+		var updatedTask interfaces.Task
+		sqlCommand := "UPDATE tasks SET version = version + 1, rank = $1, \"updatedAt\" = $2 WHERE id = $3 RETURNING " + TASK_COLUMNS + ";"
+
+		queryErr := queryTask(tx, &updatedTask, sqlCommand, rank, int(time.Now().Unix()), taskId)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_REORDER, &previousTask, &updatedTask)
+	})
+	if transactErr != nil {
+		return []interfaces.Task{}, transactErr
+	}
+
+	return RetrieveTasksByListId(listId, false)
+}
+
+// rankAtListOrder finds the rank that puts a task at newListOrder, respreading
+// the ranks of the list first if its new neighbours share a rank. moved is
+// false if the task is already there.
+func rankAtListOrder(tx db.Executor, taskId string, listId string, newListOrder int) (string, bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		sqlCommand := "SELECT id, rank FROM tasks WHERE \"listId\" = $1 AND \"deletedAt\" = -1 AND \"archivedAt\" = -1 ORDER BY rank, id"
+
+		rows, queryErr := tx.Query(sqlCommand, listId)
+		if queryErr != nil {
+			return "", false, queryErr
+		}
+
+		otherRanks := []string{}
+		listOrder := -1
+
+		for rows.Next() {
+			var id, rank string
+			scanErr := rows.Scan(&id, &rank)
+			if scanErr != nil {
+				rows.Close()
+				return "", false, scanErr
+			}
+
+			if id == taskId {
+				listOrder = len(otherRanks)
+				continue
+			}
+			otherRanks = append(otherRanks, rank)
+		}
+		rows.Close()
+
+		rowsErr := rows.Err()
+		if rowsErr != nil {
+			return "", false, rowsErr
+		}
+
+		if listOrder == -1 {
+			return "", false, fmt.Errorf("task not found in list")
+		}
+
+		if newListOrder > len(otherRanks) {
+			newListOrder = len(otherRanks)
+		}
+
+		if newListOrder == listOrder {
+			return "", false, nil
+		}
+
+		before, after := "", ""
+		if newListOrder > 0 {
+			before = otherRanks[newListOrder-1]
+		}
+		if newListOrder < len(otherRanks) {
+			after = otherRanks[newListOrder]
+		}
+
+		if after == "" || before < after {
+			rank, rankErr := rankService.Between(before, after)
+			return rank, true, rankErr
+		}
+
+		respreadErr := respreadRanks(tx, listId)
+		if respreadErr != nil {
+			return "", false, respreadErr
+		}
+	}
+
+	return "", false, fmt.Errorf("could not rank task")
+}
+
+// rankAfterList returns a rank that puts a task after every other task of a
+// list, including the ones in the trash or the archive.
+func rankAfterList(ex db.Executor, listId string) (string, error) {
+	var lastRank string
+	sqlCommand := "SELECT COALESCE(MAX(rank), '') FROM tasks WHERE \"listId\" = $1"
+
+	queryErr := ex.QueryRow(sqlCommand, listId).Scan(&lastRank)
+	if queryErr != nil {
+		return lastRank, queryErr
+	}
+
+	return rankService.Between(lastRank, "")
+}
+
+func countTasksInListOrder(ex db.Executor, listId string) (int, error) {
+	var nTasksInList int
+	sqlCommand := "SELECT COUNT(*) FROM tasks WHERE \"listId\" = $1 AND \"deletedAt\" = -1 AND \"archivedAt\" = -1;"
+
+	queryErr := ex.QueryRow(sqlCommand, listId).Scan(&nTasksInList)
+
+	return nTasksInList, queryErr
+}
+
+// RebalanceRanks respreads the ranks of every list where repeated moves made
+// them longer than MAX_RANK_LENGTH, or where tasks added at the same time
+// ended up with the same rank. The order of the tasks is kept.
+func RebalanceRanks(ex db.Executor) error {
+	var listIds []string
+	sqlCommand := "SELECT \"listId\" FROM tasks GROUP BY \"listId\" HAVING MAX(length(rank)) > $1 OR COUNT(*) > COUNT(DISTINCT rank)"
+
+	rows, queryErr := ex.Query(sqlCommand, MAX_RANK_LENGTH)
+	if queryErr != nil {
+		return queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var listId string
+		scanErr := rows.Scan(&listId)
+		if scanErr != nil {
+			return scanErr
+		}
+
+		listIds = append(listIds, listId)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return rowsErr
+	}
+
+	for _, listId := range listIds {
+		rebalanceErr := rebalanceList(ex, listId)
+		if rebalanceErr != nil {
+			return rebalanceErr
+		}
+	}
+
+	return nil
+}
+
+func rebalanceList(ex db.Executor, listId string) error {
+	return db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockList(tx, listId)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		return respreadRanks(tx, listId)
+	})
+}
+
+// respreadRanks gives every task of a list, shown or not, a new evenly spread
+// rank in the same order.
+func respreadRanks(tx db.Executor, listId string) error {
+	var taskIds []string
+	sqlCommand := "SELECT id FROM tasks WHERE \"listId\" = $1 ORDER BY rank, id"
+
+	rows, queryErr := tx.Query(sqlCommand, listId)
+	if queryErr != nil {
+		return queryErr
+	}
+
+	for rows.Next() {
+		var taskId string
+		scanErr := rows.Scan(&taskId)
+		if scanErr != nil {
+			rows.Close()
+			return scanErr
+		}
+
+		taskIds = append(taskIds, taskId)
+	}
+	rows.Close()
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return rowsErr
+	}
+
+	updateCommand := "UPDATE tasks SET rank = spread.rank FROM unnest($1::varchar[], $2::text[]) AS spread(id, rank) WHERE tasks.id = spread.id;"
+
+	_, execErr := tx.Exec(updateCommand, pq.Array(taskIds), pq.Array(rankService.Spread(len(taskIds))))
+
+	return execErr
+}
+
+// lockList makes the other transactions changing ranks in a list wait until
+// tx is done.
+func lockList(tx db.Executor, listId string) error {
+	var id string
+	sqlCommand := "SELECT id FROM lists WHERE id = $1 FOR UPDATE"
+
+	return tx.QueryRow(sqlCommand, listId).Scan(&id)
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	historyService "github.com/beebeeoii/do-gether/services/history"
+	utils "github.com/beebeeoii/do-gether/services/utils"
+)
+
+const SUBTASK_COLUMNS = "id, \"taskId\", COALESCE(\"parentId\", ''), title, \"listOrder\", completed"
+
+// Subtasks are ordered among their siblings, which share a task and a parent
+// subtask. A NULL parent is compared as equal to another NULL parent.
+const SIBLINGS_CONDITION = "\"taskId\" = $1 AND \"parentId\" IS NOT DISTINCT FROM NULLIF($2, '')"
+
+// subtaskFields returns the scan destinations of a subtask in SUBTASK_COLUMNS
+// order.
+func subtaskFields(subtask *interfaces.Subtask) []interface{} {
+	return []interface{}{
+		&subtask.Id,
+		&subtask.TaskId,
+		&subtask.ParentId,
+		&subtask.Title,
+		&subtask.ListOrder,
+		&subtask.Completed,
+	}
+}
+
+// touchParentTask bumps the version of the task a subtask belongs to, which
+// must have been locked with lockTaskAtVersion, and records the change of
+// the subtask in the history of the task.
+func touchParentTask(tx db.Executor, actorId string, taskId string, before *interfaces.Subtask, after *interfaces.Subtask) (interfaces.Task, error) {
+	var updatedTask interfaces.Task
+	sqlCommand := "UPDATE tasks SET version = version + 1, \"updatedAt\" = $1 WHERE id = $2 RETURNING " + TASK_COLUMNS + ";"
+
+	queryErr := queryTask(tx, &updatedTask, sqlCommand, int(time.Now().Unix()), taskId)
+	if queryErr != nil {
+		return updatedTask, queryErr
+	}
+
+	return updatedTask, historyService.RecordSubtaskEvent(tx, actorId, updatedTask, before, after)
+}
+
+// CreateSubtask adds a subtask to the end of its siblings, and returns it
+// along with its updated parent task.
+func CreateSubtask(ex db.Executor, actorId string, subtask interfaces.SubtaskCreationData) (interfaces.Subtask, interfaces.Task, error) {
+	var newSubtask interfaces.Subtask
+	var updatedTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		// Concurrent creations would otherwise count the same siblings and
+		// share a list order.
+		lockErr := lockTaskAtVersion(tx, subtask.TaskId, interfaces.ANY_VERSION)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		var nSiblings int
+		getTotalCommand := "SELECT COUNT(*) FROM subtasks WHERE " + SIBLINGS_CONDITION + ";"
+
+		queryErr := tx.QueryRow(getTotalCommand, subtask.TaskId, subtask.ParentId).Scan(&nSiblings)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		newSubtask = interfaces.Subtask{
+			Id:        utils.GenerateUid(),
+			TaskId:    subtask.TaskId,
+			ParentId:  subtask.ParentId,
+			Title:     subtask.Title,
+			ListOrder: nSiblings,
+			Completed: false,
+		}
+
+		insertErr := insertSubtask(tx, newSubtask)
+		if insertErr != nil {
+			return insertErr
+		}
+
+		var touchErr error
+		updatedTask, touchErr = touchParentTask(tx, actorId, subtask.TaskId, nil, &newSubtask)
+
+		return touchErr
+	})
+
+	return newSubtask, updatedTask, transactErr
+}
+
+func insertSubtask(ex db.Executor, subtask interfaces.Subtask) error {
+	sqlCommand := "INSERT INTO subtasks (id, \"taskId\", \"parentId\", title, \"listOrder\", completed) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6);"
+
+	_, execErr := ex.Exec(
+		sqlCommand,
+		subtask.Id,
+		subtask.TaskId,
+		subtask.ParentId,
+		subtask.Title,
+		subtask.ListOrder,
+		subtask.Completed,
+	)
+
+	return execErr
+}
+
+// copySubtasks copies the subtasks of a task to another, keeping their
+// nesting and order but not their completion.
+func copySubtasks(tx db.Executor, fromTaskId string, toTaskId string) error {
+	subtasks, retrieveSubtasksErr := retrieveSubtasksByTaskId(tx, fromTaskId)
+	if retrieveSubtasksErr != nil {
+		return retrieveSubtasksErr
+	}
+
+	copiedIds := map[string]string{"": ""}
+	for len(subtasks) > 0 {
+		var pending []interfaces.Subtask
+
+		for _, subtask := range subtasks {
+			parentId, isParentCopied := copiedIds[subtask.ParentId]
+			if !isParentCopied {
+				pending = append(pending, subtask)
+				continue
+			}
+
+			copiedSubtask := interfaces.Subtask{
+				Id:        utils.GenerateUid(),
+				TaskId:    toTaskId,
+				ParentId:  parentId,
+				Title:     subtask.Title,
+				ListOrder: subtask.ListOrder,
+				Completed: false,
+			}
+
+			insertErr := insertSubtask(tx, copiedSubtask)
+			if insertErr != nil {
+				return insertErr
+			}
+
+			copiedIds[subtask.Id] = copiedSubtask.Id
+		}
+
+		subtasks = pending
+	}
+
+	return nil
+}
+
+// lockSubtask locks the task a subtask belongs to and returns the subtask.
+func lockSubtask(tx db.Executor, subtaskId string) (interfaces.Subtask, error) {
+	var subtask interfaces.Subtask
+
+	taskId, retrieveTaskIdErr := retrieveTaskIdBySubtaskId(tx, subtaskId)
+	if retrieveTaskIdErr != nil {
+		return subtask, retrieveTaskIdErr
+	}
+
+	lockErr := lockTaskAtVersion(tx, taskId, interfaces.ANY_VERSION)
+	if lockErr != nil {
+		return subtask, lockErr
+	}
+
+	sqlCommand := "SELECT " + SUBTASK_COLUMNS + " FROM subtasks WHERE id = $1;"
+	queryErr := tx.QueryRow(sqlCommand, subtaskId).Scan(subtaskFields(&subtask)...)
+
+	return subtask, queryErr
+}
+
+func EditSubtask(ex db.Executor, actorId string, subtask interfaces.SubtaskEditionData) (interfaces.Subtask, interfaces.Task, error) {
+	var updatedSubtask interfaces.Subtask
+	var updatedTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		previousSubtask, lockErr := lockSubtask(tx, subtask.Id)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		sqlCommand := "UPDATE subtasks SET title = $1 WHERE id = $2 RETURNING " + SUBTASK_COLUMNS + ";"
+
+		queryErr := tx.QueryRow(sqlCommand, subtask.Title, subtask.Id).Scan(subtaskFields(&updatedSubtask)...)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		var touchErr error
+		updatedTask, touchErr = touchParentTask(tx, actorId, updatedSubtask.TaskId, &previousSubtask, &updatedSubtask)
+
+		return touchErr
+	})
+
+	return updatedSubtask, updatedTask, transactErr
+}
+
+func EditSubtaskCompleted(ex db.Executor, actorId string, subtask interfaces.SubtaskEditCompletedData) (interfaces.Subtask, interfaces.Task, error) {
+	var updatedSubtask interfaces.Subtask
+	var updatedTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		previousSubtask, lockErr := lockSubtask(tx, subtask.Id)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		sqlCommand := "UPDATE subtasks SET completed = $1 WHERE id = $2 RETURNING " + SUBTASK_COLUMNS + ";"
+
+		queryErr := tx.QueryRow(sqlCommand, subtask.Completed, subtask.Id).Scan(subtaskFields(&updatedSubtask)...)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		var touchErr error
+		updatedTask, touchErr = touchParentTask(tx, actorId, updatedSubtask.TaskId, &previousSubtask, &updatedSubtask)
+
+		return touchErr
+	})
+
+	return updatedSubtask, updatedTask, transactErr
+}
+
+// ErrListOrderOutOfRange is returned when moving a subtask past its last
+// sibling.
+var ErrListOrderOutOfRange = fmt.Errorf("new list order is out of range")
+
+// ReorderSubtask moves a subtask among its siblings, and returns all subtasks
+// of its task along with the updated task. The task stays locked while the
+// siblings are counted and shifted, so that subtasks created or deleted at the
+// same time cannot leave gaps or duplicates in their list orders.
+func ReorderSubtask(ex db.Executor, actorId string, subtaskId string, newListOrder int) ([]interfaces.Subtask, interfaces.Task, error) {
+	var subtasks []interfaces.Subtask
+	var updatedTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		taskId, retrieveTaskIdErr := retrieveTaskIdBySubtaskId(tx, subtaskId)
+		if retrieveTaskIdErr != nil {
+			return retrieveTaskIdErr
+		}
+
+		lockErr := lockTaskAtVersion(tx, taskId, interfaces.ANY_VERSION)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		var previousSubtask interfaces.Subtask
+		retrieveCommand := "SELECT " + SUBTASK_COLUMNS + " FROM subtasks WHERE id = $1;"
+
+		retrieveErr := tx.QueryRow(retrieveCommand, subtaskId).Scan(subtaskFields(&previousSubtask)...)
+		if retrieveErr != nil {
+			return retrieveErr
+		}
+
+		var nSiblings int
+		getTotalCommand := "SELECT COUNT(*) FROM subtasks WHERE " + SIBLINGS_CONDITION + ";"
+
+		countErr := tx.QueryRow(getTotalCommand, previousSubtask.TaskId, previousSubtask.ParentId).Scan(&nSiblings)
+		if countErr != nil {
+			return countErr
+		}
+
+		if newListOrder < 0 || newListOrder >= nSiblings {
+			return ErrListOrderOutOfRange
+		}
+
+		initialListOrder := previousSubtask.ListOrder
+
+		if newListOrder < initialListOrder {
+			shiftCommand := "UPDATE subtasks SET \"listOrder\" = \"listOrder\" + 1 WHERE " + SIBLINGS_CONDITION + " AND \"listOrder\" >= $3 AND \"listOrder\" < $4;"
+
+			_, shiftErr := tx.Exec(shiftCommand, previousSubtask.TaskId, previousSubtask.ParentId, newListOrder, initialListOrder)
+			if shiftErr != nil {
+				return shiftErr
+			}
+		}
+
+		if newListOrder > initialListOrder {
+			shiftCommand := "UPDATE subtasks SET \"listOrder\" = \"listOrder\" - 1 WHERE " + SIBLINGS_CONDITION + " AND \"listOrder\" > $3 AND \"listOrder\" <= $4;"
+
+			_, shiftErr := tx.Exec(shiftCommand, previousSubtask.TaskId, previousSubtask.ParentId, initialListOrder, newListOrder)
+			if shiftErr != nil {
+				return shiftErr
+			}
+		}
+
+		var updatedSubtask interfaces.Subtask
+		updateOrderCommand := "UPDATE subtasks SET \"listOrder\" = $1 WHERE id = $2 RETURNING " + SUBTASK_COLUMNS + ";"
+
+		updateErr := tx.QueryRow(updateOrderCommand, newListOrder, subtaskId).Scan(subtaskFields(&updatedSubtask)...)
+		if updateErr != nil {
+			return updateErr
+		}
+
+		var touchErr error
+		updatedTask, touchErr = touchParentTask(tx, actorId, updatedSubtask.TaskId, &previousSubtask, &updatedSubtask)
+		if touchErr != nil {
+			return touchErr
+		}
+
+		var retrieveSubtasksErr error
+		subtasks, retrieveSubtasksErr = retrieveSubtasksByTaskId(tx, updatedSubtask.TaskId)
+
+		return retrieveSubtasksErr
+	})
+
+	return subtasks, updatedTask, transactErr
+}
+
+// DeleteSubtask deletes a subtask, and with it all subtasks nested under it.
+func DeleteSubtask(ex db.Executor, actorId string, subtaskId string) (interfaces.Subtask, interfaces.Task, error) {
+	var deletedSubtask interfaces.Subtask
+	var updatedTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		_, lockErr := lockSubtask(tx, subtaskId)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		sqlCommand := "DELETE FROM subtasks WHERE id = $1 RETURNING " + SUBTASK_COLUMNS + ";"
+
+		queryErr := tx.QueryRow(sqlCommand, subtaskId).Scan(subtaskFields(&deletedSubtask)...)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		shiftCommand := "UPDATE subtasks SET \"listOrder\" = \"listOrder\" - 1 WHERE " + SIBLINGS_CONDITION + " AND \"listOrder\" > $3;"
+
+		_, shiftErr := tx.Exec(shiftCommand, deletedSubtask.TaskId, deletedSubtask.ParentId, deletedSubtask.ListOrder)
+		if shiftErr != nil {
+			return shiftErr
+		}
+
+		var touchErr error
+		updatedTask, touchErr = touchParentTask(tx, actorId, deletedSubtask.TaskId, &deletedSubtask, nil)
+
+		return touchErr
+	})
+
+	return deletedSubtask, updatedTask, transactErr
+}
+
+func DeleteSubtasksFromTask(ex db.Executor, taskId string) error {
+	sqlCommand := "DELETE FROM subtasks WHERE \"taskId\" = $1;"
+
+	_, execErr := ex.Exec(sqlCommand, taskId)
+	if execErr != nil {
+		return execErr
+	}
+
+	return nil
+}
+
+func DeleteSubtasksFromList(ex db.Executor, listId string) error {
+	sqlCommand := "DELETE FROM subtasks WHERE \"taskId\" IN (SELECT id FROM tasks WHERE \"listId\" = $1);"
+
+	_, execErr := ex.Exec(sqlCommand, listId)
+	if execErr != nil {
+		return execErr
+	}
+
+	return nil
+}
+
+// RetrieveSubtasksByTaskId returns the top-level subtasks of a task first,
+// followed by the nested ones, each in the order of their siblings.
+func RetrieveSubtasksByTaskId(taskId string) ([]interfaces.Subtask, error) {
+	return retrieveSubtasksByTaskId(db.Database, taskId)
+}
+
+func retrieveSubtasksByTaskId(ex db.Executor, taskId string) ([]interfaces.Subtask, error) {
+	var subtasks []interfaces.Subtask
+	sqlCommand := "SELECT " + SUBTASK_COLUMNS + " FROM subtasks WHERE \"taskId\" = $1 ORDER BY \"parentId\" NULLS FIRST, \"listOrder\" ASC"
+
+	rows, queryErr := ex.Query(sqlCommand, taskId)
+	if queryErr != nil {
+		return subtasks, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		subtask := interfaces.Subtask{}
+		scanErr := rows.Scan(subtaskFields(&subtask)...)
+		if scanErr != nil {
+			return subtasks, scanErr
+		}
+
+		subtasks = append(subtasks, subtask)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return subtasks, rowsErr
+	}
+
+	return subtasks, nil
+}
+
+func RetrieveTaskIdBySubtaskId(subtaskId string) (string, error) {
+	return retrieveTaskIdBySubtaskId(db.Database, subtaskId)
+}
+
+func retrieveTaskIdBySubtaskId(ex db.Executor, subtaskId string) (string, error) {
+	var taskId string
+	sqlCommand := "SELECT \"taskId\" FROM subtasks WHERE id = $1"
+
+	queryErr := ex.QueryRow(sqlCommand, subtaskId).Scan(&taskId)
+	if queryErr != nil {
+		return taskId, queryErr
+	}
+
+	return taskId, nil
+}
+
+func AreAllSubtasksCompleted(ex db.Executor, taskId string) (bool, error) {
+	var nIncompleteSubtasks int
+	sqlCommand := "SELECT COUNT(*) FROM subtasks WHERE \"taskId\" = $1 AND completed = false;"
+
+	queryErr := ex.QueryRow(sqlCommand, taskId).Scan(&nIncompleteSubtasks)
+	if queryErr != nil {
+		return false, queryErr
+	}
+
+	return nIncompleteSubtasks == 0, nil
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	historyService "github.com/beebeeoii/do-gether/services/history"
+	tagService "github.com/beebeeoii/do-gether/services/tag"
+	"github.com/lib/pq"
+)
+
+// RetrieveTagsWithUsageByListId returns every tag of a list, whether it is
+// only used on tasks or only has metadata saved, along with the number of live
+// tasks using it. Parents of nested tags are included and count the tasks of
+// their descendants.
+func RetrieveTagsWithUsageByListId(listId string) ([]interfaces.Tag, error) {
+	tags := []interfaces.Tag{}
+	sqlCommand := "SELECT name, COALESCE(meta.color, ''), COALESCE(meta.description, ''), COALESCE(used.usage, 0), COALESCE(used.\"directUsage\", 0) FROM (SELECT path AS name, COUNT(DISTINCT id) AS usage, COUNT(DISTINCT id) FILTER (WHERE path = tag) AS \"directUsage\" FROM (SELECT tasks.id, tag, array_to_string((string_to_array(tag, '" + tagService.SEPARATOR + "'))[1:depth], '" + tagService.SEPARATOR + "') AS path FROM tasks, unnest(tags) tag, generate_series(1, cardinality(string_to_array(tag, '" + tagService.SEPARATOR + "'))) depth WHERE \"listId\" = $1 AND \"deletedAt\" = -1) expanded GROUP BY path) used FULL OUTER JOIN (SELECT name, color, description FROM tags WHERE \"listId\" = $1) meta USING (name) ORDER BY name ASC"
+
+	rows, queryErr := db.Database.Query(sqlCommand, listId)
+	if queryErr != nil {
+		return tags, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		tag := interfaces.Tag{ListId: listId}
+		scanErr := rows.Scan(&tag.Name, &tag.Color, &tag.Description, &tag.Usage, &tag.DirectUsage)
+		if scanErr != nil {
+			return tags, scanErr
+		}
+
+		tags = append(tags, tag)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return tags, rowsErr
+	}
+
+	return tags, nil
+}
+
+// EditTag saves the colour and description of a tag, whether or not any task
+// uses it yet.
+func EditTag(ex db.Executor, tag interfaces.TagEditionData) (interfaces.Tag, error) {
+	updatedTag := interfaces.Tag{}
+	sqlCommand := "INSERT INTO tags (\"listId\", name, color, description) VALUES ($1, $2, $3, $4) ON CONFLICT (\"listId\", name) DO UPDATE SET color = EXCLUDED.color, description = EXCLUDED.description RETURNING \"listId\", name, color, description"
+
+	queryErr := ex.QueryRow(
+		sqlCommand,
+		tag.ListId,
+		tag.Name,
+		tag.Color,
+		tag.Description,
+	).Scan(
+		&updatedTag.ListId,
+		&updatedTag.Name,
+		&updatedTag.Color,
+		&updatedTag.Description,
+	)
+	if queryErr != nil {
+		return updatedTag, queryErr
+	}
+
+	usageCommand := "SELECT COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM unnest(tags) tag WHERE " + tagService.MatchCondition("tag", "$2") + ")), COUNT(*) FILTER (WHERE tags @> ARRAY[$2]::varchar[]) FROM tasks WHERE \"listId\" = $1 AND \"deletedAt\" = -1"
+	queryErr = ex.QueryRow(usageCommand, updatedTag.ListId, updatedTag.Name).Scan(&updatedTag.Usage, &updatedTag.DirectUsage)
+
+	return updatedTag, queryErr
+}
+
+// RenameTag replaces a tag with another on every task of a list, moving the
+// tags nested under it along. Tasks that already have the new tag simply lose
+// the old one.
+func RenameTag(ex db.Executor, actorId string, listId string, name string, newName string) ([]interfaces.Task, error) {
+	return MergeTags(ex, actorId, listId, []string{name}, newName)
+}
+
+// MergeTags replaces every one of the given tags with the target tag on every
+// task of a list, trashed and archived tasks included. Nested tags keep their
+// place under the target, so merging work into job turns work/billing into
+// job/billing. Each task array is rewritten in a single statement, keeping the
+// position of the first occurrence and dropping duplicates.
+func MergeTags(ex db.Executor, actorId string, listId string, names []string, target string) ([]interfaces.Task, error) {
+	sources := []string{}
+
+	for _, name := range names {
+		if name == target {
+			continue
+		}
+
+		if tagService.IsWithin(target, name) {
+			return []interfaces.Task{}, fmt.Errorf("cannot move %s under itself", name)
+		}
+
+		sources = append(sources, name)
+	}
+
+	if len(sources) == 0 {
+		return []interfaces.Task{}, nil
+	}
+
+	sqlCommand := "UPDATE tasks SET version = version + 1, tags = ARRAY(SELECT tag FROM (SELECT COALESCE((SELECT $3::varchar || substr(existing.tag, length(source) + 1) FROM unnest($2::varchar[]) source WHERE " + tagService.MatchCondition("existing.tag", "source") + " ORDER BY length(source) DESC LIMIT 1), existing.tag) AS tag, position FROM unnest(previous.\"previousTags\") WITH ORDINALITY AS existing(tag, position)) replaced GROUP BY tag ORDER BY MIN(position)), \"updatedAt\" = $4 FROM (SELECT id AS \"previousId\", tags AS \"previousTags\" FROM tasks WHERE \"listId\" = $1 AND EXISTS (SELECT 1 FROM unnest(tags) tag, unnest($2::varchar[]) source WHERE " + tagService.MatchCondition("tag", "source") + ") FOR UPDATE) previous WHERE tasks.id = previous.\"previousId\" RETURNING previous.\"previousTags\", " + TASK_COLUMNS
+
+	var updatedTasks []interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		var rewriteErr error
+		updatedTasks, rewriteErr = rewriteTags(tx, actorId, sqlCommand, listId, pq.Array(sources), target, int(time.Now().Unix()))
+		if rewriteErr != nil {
+			return rewriteErr
+		}
+
+		return mergeTagMetadata(tx, listId, sources, target)
+	})
+
+	return updatedTasks, transactErr
+}
+
+// DeleteTag removes a tag and the tags nested under it from every task of a
+// list and forgets their metadata.
+func DeleteTag(ex db.Executor, actorId string, listId string, name string) ([]interfaces.Task, error) {
+	sqlCommand := "UPDATE tasks SET version = version + 1, tags = ARRAY(SELECT existing.tag FROM unnest(previous.\"previousTags\") WITH ORDINALITY AS existing(tag, position) WHERE NOT " + tagService.MatchCondition("existing.tag", "$2") + " ORDER BY position), \"updatedAt\" = $3 FROM (SELECT id AS \"previousId\", tags AS \"previousTags\" FROM tasks WHERE \"listId\" = $1 AND EXISTS (SELECT 1 FROM unnest(tags) tag WHERE " + tagService.MatchCondition("tag", "$2") + ") FOR UPDATE) previous WHERE tasks.id = previous.\"previousId\" RETURNING previous.\"previousTags\", " + TASK_COLUMNS
+
+	var updatedTasks []interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		var rewriteErr error
+		updatedTasks, rewriteErr = rewriteTags(tx, actorId, sqlCommand, listId, name, int(time.Now().Unix()))
+		if rewriteErr != nil {
+			return rewriteErr
+		}
+
+		deleteCommand := "DELETE FROM tags WHERE \"listId\" = $1 AND " + tagService.MatchCondition("name", "$2") + ";"
+		_, execErr := tx.Exec(deleteCommand, listId, name)
+
+		return execErr
+	})
+
+	return updatedTasks, transactErr
+}
+
+// DeleteTagsFromList forgets the metadata of every tag of a list.
+func DeleteTagsFromList(ex db.Executor, listId string) error {
+	sqlCommand := "DELETE FROM tags WHERE \"listId\" = $1;"
+
+	_, execErr := ex.Exec(sqlCommand, listId)
+
+	return execErr
+}
+
+// rewriteTags runs an UPDATE that returns the previous tags followed by the
+// task columns, and records an edit event for every task it touched.
+func rewriteTags(ex db.Executor, actorId string, sqlCommand string, args ...interface{}) ([]interfaces.Task, error) {
+	updatedTasks := []interfaces.Task{}
+	var previousTags [][]string
+
+	rows, queryErr := ex.Query(sqlCommand, args...)
+	if queryErr != nil {
+		return updatedTasks, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tags []string
+		updatedTask := interfaces.Task{}
+		scanErr := rows.Scan(append([]interface{}{pq.Array(&tags)}, taskFields(&updatedTask)...)...)
+		if scanErr != nil {
+			return updatedTasks, scanErr
+		}
+
+		updatedTasks = append(updatedTasks, updatedTask)
+		previousTags = append(previousTags, tags)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return updatedTasks, rowsErr
+	}
+
+	listOrdersErr := attachListOrders(ex, updatedTasks)
+	if listOrdersErr != nil {
+		return updatedTasks, listOrdersErr
+	}
+
+	for i := range updatedTasks {
+		previousTask := updatedTasks[i]
+		previousTask.Tags = previousTags[i]
+
+		recordErr := historyService.RecordTaskEvent(ex, actorId, interfaces.TASK_EVENT_EDIT, &previousTask, &updatedTasks[i])
+		if recordErr != nil {
+			return updatedTasks, recordErr
+		}
+	}
+
+	return updatedTasks, nil
+}
+
+// mergeTagMetadata moves the colour and description of the merged tags and
+// their descendants over to where they were moved, unless a tag there already
+// has its own. Earlier sources win over later ones.
+func mergeTagMetadata(ex db.Executor, listId string, sources []string, target string) error {
+	sqlCommand := "DELETE FROM tags WHERE \"listId\" = $1 AND EXISTS (SELECT 1 FROM unnest($2::varchar[]) source WHERE " + tagService.MatchCondition("name", "source") + ") RETURNING name, color, description"
+
+	rows, queryErr := ex.Query(sqlCommand, listId, pq.Array(sources))
+	if queryErr != nil {
+		return queryErr
+	}
+	defer rows.Close()
+
+	movedTags := map[string]interfaces.Tag{}
+	movedFrom := map[string]int{}
+
+	for rows.Next() {
+		tag := interfaces.Tag{ListId: listId}
+		scanErr := rows.Scan(&tag.Name, &tag.Color, &tag.Description)
+		if scanErr != nil {
+			return scanErr
+		}
+
+		newName, _ := tagService.Rewrite(tag.Name, sources, target)
+		rank := sourceRank(tag.Name, sources)
+
+		if previous, ok := movedFrom[newName]; ok && previous <= rank {
+			continue
+		}
+
+		movedFrom[newName] = rank
+		tag.Name = newName
+		movedTags[newName] = tag
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return rowsErr
+	}
+
+	insertCommand := "INSERT INTO tags (\"listId\", name, color, description) VALUES ($1, $2, $3, $4) ON CONFLICT (\"listId\", name) DO NOTHING;"
+
+	for _, tag := range movedTags {
+		_, execErr := ex.Exec(insertCommand, tag.ListId, tag.Name, tag.Color, tag.Description)
+		if execErr != nil {
+			return execErr
+		}
+	}
+
+	return nil
+}
+
+// sourceRank is the position of the first source a tag is within.
+func sourceRank(tag string, sources []string) int {
+	for i, source := range sources {
+		if tagService.IsWithin(tag, source) {
+			return i
+		}
+	}
+
+	return len(sources)
+}
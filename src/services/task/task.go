@@ -1,174 +1,407 @@
 package service
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/beebeeoii/do-gether/db"
 	"github.com/beebeeoii/do-gether/interfaces"
+	historyService "github.com/beebeeoii/do-gether/services/history"
+	recurrenceService "github.com/beebeeoii/do-gether/services/recurrence"
 	utils "github.com/beebeeoii/do-gether/services/utils"
 	"github.com/lib/pq"
 )
 
-func CreateTask(task interfaces.TaskCreationData) (interfaces.Task, error) {
-	var nTasksInList int
-	getTotalCommand := "SELECT COUNT(*) FROM tasks where \"listId\" = $1;"
+const TASK_COLUMNS = "tasks.id, owner, title, description, tags, \"listId\", priority, due, \"plannedStart\", \"plannedEnd\", completed, recurrence, assignees, \"deletedAt\", \"deletedBy\", \"archivedAt\", \"createdAt\", \"updatedAt\", \"completedAt\", rank, version"
+const TASK_INSERT_COLUMNS = "id, owner, title, description, tags, \"listId\", priority, due, \"plannedStart\", \"plannedEnd\", completed, recurrence, assignees, \"deletedAt\", \"deletedBy\", \"archivedAt\", \"createdAt\", \"updatedAt\", \"completedAt\", rank, version"
+
+// taskFields returns the scan destinations of a task in TASK_COLUMNS order.
+// ListOrder is not stored, see attachListOrders.
+func taskFields(task *interfaces.Task) []interface{} {
+	return []interface{}{
+		&task.Id,
+		&task.Owner,
+		&task.Title,
+		&task.Description,
+		pq.Array(&task.Tags),
+		&task.ListId,
+		&task.Priority,
+		&task.Due,
+		&task.PlannedStart,
+		&task.PlannedEnd,
+		&task.Completed,
+		&task.Recurrence,
+		pq.Array(&task.Assignees),
+		&task.DeletedAt,
+		&task.DeletedBy,
+		&task.ArchivedAt,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.CompletedAt,
+		&task.Rank,
+		&task.Version,
+	}
+}
 
-	queryErr := db.Database.QueryRow(getTotalCommand, task.ListId).Scan(&nTasksInList)
-	if queryErr != nil {
-		return interfaces.Task{}, queryErr
+func CreateTask(ex db.Executor, actorId string, task interfaces.TaskCreationData) (interfaces.Task, error) {
+	var newTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		rank, rankErr := rankAfterList(tx, task.ListId)
+		if rankErr != nil {
+			return rankErr
+		}
+
+		nTasksInList, countErr := countTasksInListOrder(tx, task.ListId)
+		if countErr != nil {
+			return countErr
+		}
+
+		sqlCommand := "INSERT INTO tasks (" + TASK_INSERT_COLUMNS + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21);"
+
+		now := int(time.Now().Unix())
+		newTask = interfaces.Task{
+			Id:           utils.GenerateUid(),
+			Owner:        task.Owner,
+			Title:        task.Title,
+			Description:  task.Description,
+			Tags:         task.Tags,
+			ListId:       task.ListId,
+			ListOrder:    nTasksInList,
+			Priority:     task.Priority,
+			Due:          task.Due,
+			PlannedStart: task.PlannedStart,
+			PlannedEnd:   task.PlannedEnd,
+			Completed:    false,
+			Recurrence:   task.Recurrence,
+			Assignees:    task.Assignees,
+			DeletedAt:    -1,
+			DeletedBy:    "",
+			ArchivedAt:   -1,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			CompletedAt:  -1,
+			Rank:         rank,
+			Version:      1,
+		}
+
+		_, execErr := tx.Exec(
+			sqlCommand,
+			newTask.Id,
+			newTask.Owner,
+			newTask.Title,
+			newTask.Description,
+			pq.Array(newTask.Tags),
+			newTask.ListId,
+			newTask.Priority,
+			newTask.Due,
+			newTask.PlannedStart,
+			newTask.PlannedEnd,
+			newTask.Completed,
+			newTask.Recurrence,
+			pq.Array(newTask.Assignees),
+			newTask.DeletedAt,
+			newTask.DeletedBy,
+			newTask.ArchivedAt,
+			newTask.CreatedAt,
+			newTask.UpdatedAt,
+			newTask.CompletedAt,
+			newTask.Rank,
+			newTask.Version,
+		)
+		if execErr != nil {
+			return execErr
+		}
+
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_CREATE, nil, &newTask)
+	})
+
+	return newTask, transactErr
+}
+
+func EditTask(ex db.Executor, actorId string, task interfaces.TaskEditionData) (interfaces.Task, error) {
+	var updatedTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockTaskAtVersion(tx, task.Id, task.Version)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		previousTask, retrieveErr := retrieveTaskById(tx, task.Id)
+		if retrieveErr != nil {
+			return retrieveErr
+		}
+
+		sqlCommand := "UPDATE tasks SET version = version + 1, title = $1, description = $2, tags = $3, priority = $4, due = $5, \"plannedStart\" = $6, \"plannedEnd\" = $7, recurrence = $8, assignees = $9, \"updatedAt\" = $10 WHERE id = $11 RETURNING " + TASK_COLUMNS + ";"
+
+		queryErr := queryTask(
+			tx,
+			&updatedTask,
+			sqlCommand,
+			task.Title,
+			task.Description,
+			pq.Array(task.Tags),
+			task.Priority,
+			task.Due,
+			task.PlannedStart,
+			task.PlannedEnd,
+			task.Recurrence,
+			pq.Array(task.Assignees),
+			int(time.Now().Unix()),
+			task.Id,
+		)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_EDIT, &previousTask, &updatedTask)
+	})
+
+	return updatedTask, transactErr
+}
+
+// ErrTaskBlocked is returned when completing a task that open tasks block.
+var ErrTaskBlocked = fmt.Errorf("task is blocked")
+
+// EditTaskCompleted completes or reopens a task. Completing a recurring task
+// spawns its next occurrence, with the recurrence expanded in location.
+// Completing a task that open tasks block fails with ErrTaskBlocked unless
+// it is forced.
+func EditTaskCompleted(ex db.Executor, actorId string, task interfaces.TaskEditCompletedData, location *time.Location) (interfaces.Task, *interfaces.Task, error) {
+	var updatedTask interfaces.Task
+	var nextTask *interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockTaskAtVersion(tx, task.Id, task.Version)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		previousTask, retrieveErr := retrieveTaskById(tx, task.Id)
+		if retrieveErr != nil {
+			return retrieveErr
+		}
+
+		// Blockers cannot be added while the task is locked, as
+		// CreateTaskDependency locks the dependent task too.
+		if task.Completed && !task.Force {
+			openBlockerIds, retrieveBlockersErr := RetrieveOpenBlockerIds(tx, task.Id)
+			if retrieveBlockersErr != nil {
+				return retrieveBlockersErr
+			}
+
+			if len(openBlockerIds) > 0 {
+				return fmt.Errorf("%w by %d open task(s)", ErrTaskBlocked, len(openBlockerIds))
+			}
+		}
+
+		// Re-completing an already completed task keeps its original completedAt.
+		sqlCommand := "UPDATE tasks SET version = version + 1, completed = $1, \"completedAt\" = CASE WHEN NOT $1 THEN -1 WHEN completed THEN \"completedAt\" ELSE $2 END, \"updatedAt\" = $2 WHERE id = $3 RETURNING " + TASK_COLUMNS + ";"
+
+		queryErr := queryTask(
+			tx,
+			&updatedTask,
+			sqlCommand,
+			task.Completed,
+			int(time.Now().Unix()),
+			task.Id,
+		)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		if !previousTask.Completed && updatedTask.Completed && updatedTask.Recurrence != "" {
+			var spawnErr error
+			updatedTask, nextTask, spawnErr = spawnNextOccurrence(tx, actorId, updatedTask, location)
+			if spawnErr != nil {
+				return spawnErr
+			}
+		}
+
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_COMPLETE, &previousTask, &updatedTask)
+	})
+
+	return updatedTask, nextTask, transactErr
+}
+
+// spawnNextOccurrence creates the next task in a recurring series and hands
+// the recurrence over to it, so that un-completing and re-completing the
+// finished occurrence does not spawn duplicates.
+func spawnNextOccurrence(tx db.Executor, actorId string, task interfaces.Task, location *time.Location) (interfaces.Task, *interfaces.Task, error) {
+	rule, parseErr := recurrenceService.Parse(task.Recurrence)
+	if parseErr != nil {
+		return task, nil, parseErr
+	}
+
+	anchor := time.Now().Unix()
+	for _, timestamp := range []int{task.Due, task.PlannedStart, task.PlannedEnd} {
+		if timestamp != -1 {
+			anchor = int64(timestamp)
+			break
+		}
+	}
+
+	nextOccurrence, nextRule, hasNext := rule.Next(time.Unix(anchor, 0).In(location))
+	if !hasNext {
+		return task, nil, nil
 	}
 
-	sqlCommand := "INSERT INTO tasks (id, owner, title, tags, \"listId\", \"listOrder\", priority, due, \"plannedStart\", \"plannedEnd\", completed) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);"
+	shift := int(nextOccurrence.Unix() - anchor)
 
-	newTask := interfaces.Task{
-		Id:           utils.GenerateUid(),
+	nextTask, createErr := CreateTask(tx, actorId, interfaces.TaskCreationData{
 		Owner:        task.Owner,
 		Title:        task.Title,
+		Description:  task.Description,
 		Tags:         task.Tags,
 		ListId:       task.ListId,
-		ListOrder:    nTasksInList,
 		Priority:     task.Priority,
-		Due:          task.Due,
-		PlannedStart: task.PlannedStart,
-		PlannedEnd:   task.PlannedEnd,
-		Completed:    false,
+		Due:          shiftTimestamp(task.Due, shift),
+		PlannedStart: shiftTimestamp(task.PlannedStart, shift),
+		PlannedEnd:   shiftTimestamp(task.PlannedEnd, shift),
+		Recurrence:   nextRule.String(),
+		Assignees:    task.Assignees,
+	})
+	if createErr != nil {
+		return task, nil, createErr
 	}
 
-	_, execErr := db.Database.Exec(
-		sqlCommand,
-		newTask.Id,
-		newTask.Owner,
-		newTask.Title,
-		pq.Array(newTask.Tags),
-		newTask.ListId,
-		newTask.ListOrder,
-		newTask.Priority,
-		newTask.Due,
-		newTask.PlannedStart,
-		newTask.PlannedEnd,
-		newTask.Completed,
-	)
-
-	return newTask, execErr
+	copySubtasksErr := copySubtasks(tx, task.Id, nextTask.Id)
+	if copySubtasksErr != nil {
+		return task, &nextTask, copySubtasksErr
+	}
+
+	clearRecurrenceCommand := "UPDATE tasks SET version = version + 1, recurrence = '' WHERE id = $1 RETURNING version;"
+
+	clearErr := tx.QueryRow(clearRecurrenceCommand, task.Id).Scan(&task.Version)
+	if clearErr != nil {
+		return task, &nextTask, clearErr
+	}
+	task.Recurrence = ""
+
+	return task, &nextTask, nil
 }
 
-func EditTask(task interfaces.TaskEditionData) (interfaces.Task, error) {
-	var updatedTask interfaces.Task
-	sqlCommand := "UPDATE tasks SET title = $1, tags = $2, priority = $3, due = $4, \"plannedStart\" = $5, \"plannedEnd\" = $6 WHERE id = $7 RETURNING *;"
-
-	queryErr := db.Database.QueryRow(
-		sqlCommand,
-		task.Title,
-		pq.Array(task.Tags),
-		task.Priority,
-		task.Due,
-		task.PlannedStart,
-		task.PlannedEnd,
-		task.Id,
-	).Scan(
-		&updatedTask.Id,
-		&updatedTask.Owner,
-		&updatedTask.Title,
-		pq.Array(&updatedTask.Tags),
-		&updatedTask.ListId,
-		&updatedTask.ListOrder,
-		&updatedTask.Priority,
-		&updatedTask.Due,
-		&updatedTask.PlannedStart,
-		&updatedTask.PlannedEnd,
-		&updatedTask.Completed,
-	)
-
-	return updatedTask, queryErr
+func shiftTimestamp(timestamp int, shift int) int {
+	if timestamp == -1 {
+		return -1
+	}
+
+	return timestamp + shift
 }
 
-func EditTaskCompleted(task interfaces.TaskEditCompletedData) (interfaces.Task, error) {
+// MoveTask moves a task to the end of another list.
+func MoveTask(ex db.Executor, actorId string, task interfaces.MoveTaskData) (interfaces.Task, error) {
 	var updatedTask interfaces.Task
-	sqlCommand := "UPDATE tasks SET completed = $1 WHERE id = $2 RETURNING *;"
-
-	queryErr := db.Database.QueryRow(
-		sqlCommand,
-		task.Completed,
-		task.Id,
-	).Scan(
-		&updatedTask.Id,
-		&updatedTask.Owner,
-		&updatedTask.Title,
-		pq.Array(&updatedTask.Tags),
-		&updatedTask.ListId,
-		&updatedTask.ListOrder,
-		&updatedTask.Priority,
-		&updatedTask.Due,
-		&updatedTask.PlannedStart,
-		&updatedTask.PlannedEnd,
-		&updatedTask.Completed,
-	)
-
-	return updatedTask, queryErr
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockListErr := lockList(tx, task.NewListId)
+		if lockListErr != nil {
+			return lockListErr
+		}
+
+		lockTaskErr := lockTaskAtVersion(tx, task.Id, task.Version)
+		if lockTaskErr != nil {
+			return lockTaskErr
+		}
+
+		previousTask, retrieveErr := retrieveTaskById(tx, task.Id)
+		if retrieveErr != nil {
+			return retrieveErr
+		}
+
+		rank, rankErr := rankAfterList(tx, task.NewListId)
+		if rankErr != nil {
+			return rankErr
+		}
+
+		sqlCommand := "UPDATE tasks SET version = version + 1, \"listId\" = $1, rank = $2, \"updatedAt\" = $3 WHERE id = $4 RETURNING " + TASK_COLUMNS + ";"
+
+		queryErr := queryTask(
+			tx,
+			&updatedTask,
+			sqlCommand,
+			task.NewListId,
+			rank,
+			int(time.Now().Unix()),
+			task.Id,
+		)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_MOVE, &previousTask, &updatedTask)
+	})
+
+	return updatedTask, transactErr
 }
 
-func MoveTask(task interfaces.MoveTaskData) (interfaces.Task, error) {
+func EditTaskAssignees(ex db.Executor, actorId string, task interfaces.TaskEditAssigneesData) (interfaces.Task, error) {
 	var updatedTask interfaces.Task
-	sqlCommand := "UPDATE tasks SET \"listId\" = $1, \"listOrder\" = $2 WHERE id = $3 RETURNING *;"
-
-	queryErr := db.Database.QueryRow(
-		sqlCommand,
-		task.NewListId,
-		task.NewListOrder,
-		task.Id,
-	).Scan(
-		&updatedTask.Id,
-		&updatedTask.Owner,
-		&updatedTask.Title,
-		pq.Array(&updatedTask.Tags),
-		&updatedTask.ListId,
-		&updatedTask.ListOrder,
-		&updatedTask.Priority,
-		&updatedTask.Due,
-		&updatedTask.PlannedStart,
-		&updatedTask.PlannedEnd,
-		&updatedTask.Completed,
-	)
-
-	return updatedTask, queryErr
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		lockErr := lockTaskAtVersion(tx, task.Id, task.Version)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		previousTask, retrieveErr := retrieveTaskById(tx, task.Id)
+		if retrieveErr != nil {
+			return retrieveErr
+		}
+
+		sqlCommand := "UPDATE tasks SET version = version + 1, assignees = $1, \"updatedAt\" = $2 WHERE id = $3 RETURNING " + TASK_COLUMNS + ";"
+
+		queryErr := queryTask(
+			tx,
+			&updatedTask,
+			sqlCommand,
+			pq.Array(task.Assignees),
+			int(time.Now().Unix()),
+			task.Id,
+		)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_EDIT, &previousTask, &updatedTask)
+	})
+
+	return updatedTask, transactErr
 }
 
-func DeleteTask(taskId string) (interfaces.Task, error) {
+func DeleteTask(ex db.Executor, actorId string, taskId string) (interfaces.Task, error) {
 	var deletedTask interfaces.Task
-	sqlCommand := "DELETE FROM tasks WHERE id = $1 RETURNING *;"
-
-	queryErr := db.Database.QueryRow(
-		sqlCommand,
-		taskId,
-	).Scan(
-		&deletedTask.Id,
-		&deletedTask.Owner,
-		&deletedTask.Title,
-		pq.Array(&deletedTask.Tags),
-		&deletedTask.ListId,
-		&deletedTask.ListOrder,
-		&deletedTask.Priority,
-		&deletedTask.Due,
-		&deletedTask.PlannedStart,
-		&deletedTask.PlannedEnd,
-		&deletedTask.Completed,
-	)
-
-	return deletedTask, queryErr
-}
 
-func DeleteTasksFromList(listId string) error {
-	sqlCommand := "DELETE FROM tasks WHERE \"listId\" = $1 RETURNING *;"
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		sqlCommand := "UPDATE tasks SET version = version + 1, \"deletedAt\" = $1, \"deletedBy\" = $2 WHERE id = $3 AND \"deletedAt\" = -1 RETURNING " + TASK_COLUMNS + ";"
 
-	_, queryErr := db.Database.Exec(sqlCommand, listId)
-	if queryErr != nil {
-		return queryErr
-	}
+		queryErr := queryTask(
+			tx,
+			&deletedTask,
+			sqlCommand,
+			int(time.Now().Unix()),
+			actorId,
+			taskId,
+		)
+		if queryErr != nil {
+			return queryErr
+		}
 
-	return nil
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_DELETE, &deletedTask, nil)
+	})
+
+	return deletedTask, transactErr
 }
 
-func RetrieveTasksByListId(listId string) ([]interfaces.Task, error) {
+func RetrieveTasksByListId(listId string, includeArchived bool) ([]interfaces.Task, error) {
 	var tasks []interfaces.Task
-	sqlCommand := "SELECT * FROM tasks WHERE \"listId\" = $1 ORDER BY \"listOrder\" ASC"
+	sqlCommand := "SELECT " + TASK_COLUMNS + " FROM tasks WHERE \"listId\" = $1 AND \"deletedAt\" = -1"
+
+	if !includeArchived {
+		sqlCommand += " AND \"archivedAt\" = -1"
+	}
+	sqlCommand += " ORDER BY rank, tasks.id"
 
 	rows, queryErr := db.Database.Query(sqlCommand, listId)
 	if queryErr != nil {
@@ -177,19 +410,7 @@ func RetrieveTasksByListId(listId string) ([]interfaces.Task, error) {
 
 	for rows.Next() {
 		task := interfaces.Task{}
-		scanErr := rows.Scan(
-			&task.Id,
-			&task.Owner,
-			&task.Title,
-			pq.Array(&task.Tags),
-			&task.ListId,
-			&task.ListOrder,
-			&task.Priority,
-			&task.Due,
-			&task.PlannedStart,
-			&task.PlannedEnd,
-			&task.Completed,
-		)
+		scanErr := rows.Scan(taskFields(&task)...)
 		if scanErr != nil {
 			return tasks, scanErr
 		}
@@ -202,75 +423,93 @@ func RetrieveTasksByListId(listId string) ([]interfaces.Task, error) {
 		return tasks, rowsErr
 	}
 
+	listOrdersErr := attachListOrders(db.Database, tasks)
+	if listOrdersErr != nil {
+		return tasks, listOrdersErr
+	}
+
+	dependenciesErr := attachDependencies(tasks)
+	if dependenciesErr != nil {
+		return tasks, dependenciesErr
+	}
+
 	return tasks, nil
 }
 
-func RetrieveTagsByListId(listId string) ([]string, error) {
-	var tags []string
-	sqlCommand := "SELECT tags FROM tasks WHERE \"listId\" = $1"
+func RetrieveTasksByAssignee(userId string) ([]interfaces.Task, error) {
+	var tasks []interfaces.Task
+	sqlCommand := "SELECT " + TASK_COLUMNS + " FROM tasks WHERE assignees @> ARRAY[$1]::varchar[] AND \"deletedAt\" = -1 AND \"archivedAt\" = -1 ORDER BY \"listId\", rank, tasks.id"
 
-	rows, queryErr := db.Database.Query(sqlCommand, listId)
+	rows, queryErr := db.Database.Query(sqlCommand, userId)
 	if queryErr != nil {
-		return tags, queryErr
+		return tasks, queryErr
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		taskTags := []string{}
-		scanErr := rows.Scan(pq.Array(&taskTags))
+		task := interfaces.Task{}
+		scanErr := rows.Scan(taskFields(&task)...)
 		if scanErr != nil {
-			return tags, scanErr
+			return tasks, scanErr
 		}
 
-		tags = append(tags, taskTags...)
+		tasks = append(tasks, task)
 	}
 
 	rowsErr := rows.Err()
 	if rowsErr != nil {
-		return tags, rowsErr
+		return tasks, rowsErr
+	}
+
+	listOrdersErr := attachListOrders(db.Database, tasks)
+	if listOrdersErr != nil {
+		return tasks, listOrdersErr
 	}
 
-	return removeDuplicateStr(tags), nil
+	return tasks, nil
 }
 
-func RetrieveListIdByTaskId(taskId string) (string, error) {
-	var listId string
-	sqlCommand := "SELECT \"listId\" FROM tasks WHERE id = $1"
+func RetrieveTaskById(taskId string) (interfaces.Task, error) {
+	return retrieveTaskById(db.Database, taskId)
+}
 
-	queryErr := db.Database.QueryRow(sqlCommand, taskId).Scan(&listId)
+// lockTaskAtVersion locks a task for the rest of the transaction, failing with
+// db.ErrVersionConflict if it is no longer at the given version.
+func lockTaskAtVersion(tx db.Executor, taskId string, version int) error {
+	var currentVersion int
+	sqlCommand := "SELECT version FROM tasks WHERE id = $1 AND \"deletedAt\" = -1 FOR UPDATE"
+
+	queryErr := tx.QueryRow(sqlCommand, taskId).Scan(&currentVersion)
 	if queryErr != nil {
-		return listId, queryErr
+		return queryErr
 	}
 
-	return listId, nil
+	if version != interfaces.ANY_VERSION && version != currentVersion {
+		return db.ErrVersionConflict
+	}
+
+	return nil
 }
 
-func ReorderTasksInList(tasks []interfaces.BasicTaskReorderData) ([]interfaces.Task, error) {
-	var updatedTasks []interfaces.Task
-	sqlCommand := "UPDATE tasks SET \"listOrder\" = $1 WHERE id = $2 RETURNING *"
-
-	for _, task := range tasks {
-		updatedTask := interfaces.Task{}
-		queryErr := db.Database.QueryRow(sqlCommand, task.ListOrder, task.Id).Scan(
-			&updatedTask.Id,
-			&updatedTask.Owner,
-			&updatedTask.Title,
-			pq.Array(&updatedTask.Tags),
-			&updatedTask.ListId,
-			&updatedTask.ListOrder,
-			&updatedTask.Priority,
-			&updatedTask.Due,
-			&updatedTask.PlannedStart,
-			&updatedTask.PlannedEnd,
-			&updatedTask.Completed,
-		)
+func retrieveTaskById(ex db.Executor, taskId string) (interfaces.Task, error) {
+	var task interfaces.Task
+	sqlCommand := "SELECT " + TASK_COLUMNS + " FROM tasks WHERE id = $1 AND \"deletedAt\" = -1"
 
-		if queryErr != nil {
-			return updatedTasks, queryErr
-		}
-		updatedTasks = append(updatedTasks, updatedTask)
+	queryErr := queryTask(ex, &task, sqlCommand, taskId)
+
+	return task, queryErr
+}
+
+func RetrieveListIdByTaskId(taskId string) (string, error) {
+	var listId string
+	sqlCommand := "SELECT \"listId\" FROM tasks WHERE id = $1 AND \"deletedAt\" = -1"
+
+	queryErr := db.Database.QueryRow(sqlCommand, taskId).Scan(&listId)
+	if queryErr != nil {
+		return listId, queryErr
 	}
 
-	return updatedTasks, nil
+	return listId, nil
 }
 
 // func ReorderTask(tasks []interfaces.TaskReorderData) ([]interfaces.Task, error) {
@@ -301,17 +540,3 @@ func ReorderTasksInList(tasks []interfaces.BasicTaskReorderData) ([]interfaces.T
 
 // 	return updatedTasks, nil
 // }
-
-func removeDuplicateStr(tagSlice []string) []string {
-	tags := make(map[string]bool)
-	uniqueTagSlice := []string{}
-
-	for _, tag := range tagSlice {
-		if _, value := tags[tag]; !value {
-			tags[tag] = true
-			uniqueTagSlice = append(uniqueTagSlice, tag)
-		}
-	}
-
-	return uniqueTagSlice
-}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	attachmentService "github.com/beebeeoii/do-gether/services/attachment"
+	commentService "github.com/beebeeoii/do-gether/services/comment"
+	historyService "github.com/beebeeoii/do-gether/services/history"
+)
+
+// RestoreTask takes a task out of the trash and appends it to the end of its
+// list, or back into the archive if it was archived.
+func RestoreTask(ex db.Executor, actorId string, taskId string) (interfaces.Task, error) {
+	var restoredTask interfaces.Task
+
+	transactErr := db.Transact(ex, func(tx db.Executor) error {
+		deletedTask, retrieveErr := retrieveDeletedTaskById(tx, taskId)
+		if retrieveErr != nil {
+			return retrieveErr
+		}
+
+		rank, rankErr := rankAfterList(tx, deletedTask.ListId)
+		if rankErr != nil {
+			return rankErr
+		}
+
+		sqlCommand := "UPDATE tasks SET version = version + 1, \"deletedAt\" = -1, \"deletedBy\" = '', rank = CASE WHEN \"archivedAt\" = -1 THEN $1 ELSE rank END WHERE id = $2 AND \"deletedAt\" <> -1 RETURNING " + TASK_COLUMNS + ";"
+
+		queryErr := queryTask(tx, &restoredTask, sqlCommand, rank, taskId)
+		if queryErr != nil {
+			return queryErr
+		}
+
+		return historyService.RecordTaskEvent(tx, actorId, interfaces.TASK_EVENT_RESTORE, nil, &restoredTask)
+	})
+
+	return restoredTask, transactErr
+}
+
+func RetrieveDeletedTaskById(taskId string) (interfaces.Task, error) {
+	return retrieveDeletedTaskById(db.Database, taskId)
+}
+
+func retrieveDeletedTaskById(ex db.Executor, taskId string) (interfaces.Task, error) {
+	var task interfaces.Task
+	sqlCommand := "SELECT " + TASK_COLUMNS + " FROM tasks WHERE id = $1 AND \"deletedAt\" <> -1"
+
+	queryErr := queryTask(ex, &task, sqlCommand, taskId)
+
+	return task, queryErr
+}
+
+// RetrieveDeletedTasksByUserId returns the tasks the user sent to the trash,
+// leaving out those whose list is in the trash as well since they are
+// restored together with the list.
+func RetrieveDeletedTasksByUserId(userId string) ([]interfaces.Task, error) {
+	var tasks []interfaces.Task
+	sqlCommand := "SELECT " + TASK_COLUMNS + " FROM tasks WHERE \"deletedBy\" = $1 AND \"deletedAt\" <> -1 AND \"listId\" IN (SELECT id FROM lists WHERE \"deletedAt\" = -1) ORDER BY \"deletedAt\" DESC"
+
+	rows, queryErr := db.Database.Query(sqlCommand, userId)
+	if queryErr != nil {
+		return tasks, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task := interfaces.Task{}
+		scanErr := rows.Scan(taskFields(&task)...)
+		if scanErr != nil {
+			return tasks, scanErr
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return tasks, rowsErr
+	}
+
+	listOrdersErr := attachListOrders(db.Database, tasks)
+	if listOrdersErr != nil {
+		return tasks, listOrdersErr
+	}
+
+	return tasks, nil
+}
+
+func RetrieveExpiredTaskIds(deletedBefore int) ([]string, error) {
+	var taskIds []string
+	sqlCommand := "SELECT id FROM tasks WHERE \"deletedAt\" <> -1 AND \"deletedAt\" < $1"
+
+	rows, queryErr := db.Database.Query(sqlCommand, deletedBefore)
+	if queryErr != nil {
+		return taskIds, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskId string
+		scanErr := rows.Scan(&taskId)
+		if scanErr != nil {
+			return taskIds, scanErr
+		}
+
+		taskIds = append(taskIds, taskId)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return taskIds, rowsErr
+	}
+
+	return taskIds, nil
+}
+
+// PurgeTask permanently deletes a task together with everything hanging off
+// it.
+func PurgeTask(ex db.Executor, taskId string) error {
+	return db.Transact(ex, func(tx db.Executor) error {
+		deleteSubtasksErr := DeleteSubtasksFromTask(tx, taskId)
+		if deleteSubtasksErr != nil {
+			return deleteSubtasksErr
+		}
+
+		deleteCommentsErr := commentService.DeleteCommentsFromTask(tx, taskId)
+		if deleteCommentsErr != nil {
+			return deleteCommentsErr
+		}
+
+		deleteAttachmentsErr := attachmentService.DeleteAttachmentsFromTask(tx, taskId)
+		if deleteAttachmentsErr != nil {
+			return deleteAttachmentsErr
+		}
+
+		deleteDependenciesErr := DeleteDependenciesOfTask(tx, taskId)
+		if deleteDependenciesErr != nil {
+			return deleteDependenciesErr
+		}
+
+		deleteEventsErr := historyService.DeleteTaskEvents(tx, taskId)
+		if deleteEventsErr != nil {
+			return deleteEventsErr
+		}
+
+		sqlCommand := "DELETE FROM tasks WHERE id = $1;"
+
+		_, execErr := tx.Exec(sqlCommand, taskId)
+
+		return execErr
+	})
+}
+
+// PurgeTasksFromList permanently deletes every task of a list, including the
+// ones already in the trash.
+func PurgeTasksFromList(ex db.Executor, listId string) error {
+	return db.Transact(ex, func(tx db.Executor) error {
+		deleteSubtasksErr := DeleteSubtasksFromList(tx, listId)
+		if deleteSubtasksErr != nil {
+			return deleteSubtasksErr
+		}
+
+		deleteCommentsErr := commentService.DeleteCommentsFromList(tx, listId)
+		if deleteCommentsErr != nil {
+			return deleteCommentsErr
+		}
+
+		deleteAttachmentsErr := attachmentService.DeleteAttachmentsFromList(tx, listId)
+		if deleteAttachmentsErr != nil {
+			return deleteAttachmentsErr
+		}
+
+		deleteDependenciesErr := DeleteDependenciesFromList(tx, listId)
+		if deleteDependenciesErr != nil {
+			return deleteDependenciesErr
+		}
+
+		deleteTagsErr := DeleteTagsFromList(tx, listId)
+		if deleteTagsErr != nil {
+			return deleteTagsErr
+		}
+
+		deleteEventsErr := historyService.DeleteTaskEventsFromList(tx, listId)
+		if deleteEventsErr != nil {
+			return deleteEventsErr
+		}
+
+		sqlCommand := "DELETE FROM tasks WHERE \"listId\" = $1;"
+
+		_, execErr := tx.Exec(sqlCommand, listId)
+
+		return execErr
+	})
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	queryService "github.com/beebeeoii/do-gether/services/query"
+	"github.com/lib/pq"
+)
+
+const OPEN_TASK_CONDITION = "\"listId\" = ANY($1) AND completed = false AND \"deletedAt\" = -1 AND \"archivedAt\" = -1"
+
+// RetrieveOpenTasksDueBetween returns the open tasks of the given lists that
+// are due in [from, to).
+func RetrieveOpenTasksDueBetween(listIds []string, from int, to int) ([]interfaces.Task, error) {
+	sqlCommand := "SELECT " + TASK_COLUMNS + " FROM tasks WHERE " + OPEN_TASK_CONDITION + " AND due <> -1 AND due >= $2 AND due < $3 ORDER BY due ASC, \"listId\", rank, tasks.id"
+
+	return retrieveTasksInLists(sqlCommand, pq.Array(listIds), from, to)
+}
+
+// RetrieveOpenTasksPlannedBetween returns the open tasks of the given lists
+// whose planned period overlaps [from, to). Tasks without a planned end only
+// count on the day they are planned to start.
+func RetrieveOpenTasksPlannedBetween(listIds []string, from int, to int) ([]interfaces.Task, error) {
+	sqlCommand := "SELECT " + TASK_COLUMNS + " FROM tasks WHERE " + OPEN_TASK_CONDITION + " AND \"plannedStart\" <> -1 AND \"plannedStart\" < $3 AND (\"plannedEnd\" >= $2 OR (\"plannedEnd\" = -1 AND \"plannedStart\" >= $2)) ORDER BY \"plannedStart\" ASC, \"listId\", rank, tasks.id"
+
+	return retrieveTasksInLists(sqlCommand, pq.Array(listIds), from, to)
+}
+
+func retrieveTasksInLists(sqlCommand string, args ...interface{}) ([]interfaces.Task, error) {
+	tasks := []interfaces.Task{}
+
+	rows, queryErr := db.Database.Query(sqlCommand, args...)
+	if queryErr != nil {
+		return tasks, queryErr
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		task := interfaces.Task{}
+		scanErr := rows.Scan(taskFields(&task)...)
+		if scanErr != nil {
+			return tasks, scanErr
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return tasks, rowsErr
+	}
+
+	listOrdersErr := attachListOrders(db.Database, tasks)
+	if listOrdersErr != nil {
+		return tasks, listOrdersErr
+	}
+
+	dependenciesErr := attachDependencies(tasks)
+	if dependenciesErr != nil {
+		return tasks, dependenciesErr
+	}
+
+	return tasks, nil
+}
+
+// RetrieveTasksByQuery returns the tasks of the given lists that match a
+// compiled query. The query's placeholders must start at $2.
+func RetrieveTasksByQuery(listIds []string, query queryService.Compiled) ([]interfaces.Task, error) {
+	sqlCommand := "SELECT " + TASK_COLUMNS + " FROM tasks WHERE \"listId\" = ANY($1) AND \"deletedAt\" = -1"
+
+	if !query.IncludeArchived {
+		sqlCommand += " AND \"archivedAt\" = -1"
+	}
+	sqlCommand += " AND (" + query.Condition + ") ORDER BY \"listId\", rank, tasks.id"
+
+	return retrieveTasksInLists(sqlCommand, append([]interface{}{pq.Array(listIds)}, query.Args...)...)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/beebeeoii/do-gether/db"
+	"github.com/beebeeoii/do-gether/interfaces"
+	listService "github.com/beebeeoii/do-gether/services/list"
+	taskService "github.com/beebeeoii/do-gether/services/task"
+)
+
+const (
+	DEFAULT_RETENTION_DAYS = 30
+	PURGE_INTERVAL         = time.Hour
+	SECONDS_PER_DAY        = 24 * 60 * 60
+)
+
+// RetentionDays is how long deleted tasks and lists stay restorable before
+// the purge job removes them for good.
+var RetentionDays = DEFAULT_RETENTION_DAYS
+
+// Init reads TRASH_RETENTION_DAYS and starts the background purge job.
+func Init() (err error) {
+	TRASH_RETENTION_DAYS := os.Getenv("TRASH_RETENTION_DAYS")
+	if TRASH_RETENTION_DAYS != "" {
+		retentionDays, parseErr := strconv.Atoi(TRASH_RETENTION_DAYS)
+		if parseErr != nil || retentionDays < 0 {
+			return fmt.Errorf("invalid trash retention %q", TRASH_RETENTION_DAYS)
+		}
+		RetentionDays = retentionDays
+	}
+
+	go func() {
+		for {
+			purgeErr := PurgeExpired()
+			if purgeErr != nil {
+				log.Println(purgeErr)
+			}
+
+			time.Sleep(PURGE_INTERVAL)
+		}
+	}()
+
+	return nil
+}
+
+// PurgeExpired permanently deletes every list and task that has been in the
+// trash for longer than RetentionDays.
+func PurgeExpired() error {
+	deletedBefore := int(time.Now().Unix()) - RetentionDays*SECONDS_PER_DAY
+
+	listIds, retrieveListIdsErr := listService.RetrieveExpiredListIds(deletedBefore)
+	if retrieveListIdsErr != nil {
+		return retrieveListIdsErr
+	}
+
+	for _, listId := range listIds {
+		purgeErr := db.Transact(db.Database, func(tx db.Executor) error {
+			purgeTasksErr := taskService.PurgeTasksFromList(tx, listId)
+			if purgeTasksErr != nil {
+				return purgeTasksErr
+			}
+
+			return listService.PurgeList(tx, listId)
+		})
+		if purgeErr != nil {
+			return purgeErr
+		}
+	}
+
+	taskIds, retrieveTaskIdsErr := taskService.RetrieveExpiredTaskIds(deletedBefore)
+	if retrieveTaskIdsErr != nil {
+		return retrieveTaskIdsErr
+	}
+
+	for _, taskId := range taskIds {
+		purgeTaskErr := taskService.PurgeTask(db.Database, taskId)
+		if purgeTaskErr != nil {
+			return purgeTaskErr
+		}
+	}
+
+	return nil
+}
+
+func RetrieveTrash(userId string) (interfaces.Trash, error) {
+	trash := interfaces.Trash{
+		Tasks:         []interfaces.Task{},
+		Lists:         []interfaces.List{},
+		RetentionDays: RetentionDays,
+	}
+
+	lists, retrieveListsErr := listService.RetrieveDeletedListsByUserId(userId)
+	if retrieveListsErr != nil {
+		return trash, retrieveListsErr
+	}
+	trash.Lists = append(trash.Lists, lists...)
+
+	tasks, retrieveTasksErr := taskService.RetrieveDeletedTasksByUserId(userId)
+	if retrieveTasksErr != nil {
+		return trash, retrieveTasksErr
+	}
+	trash.Tasks = append(trash.Tasks, tasks...)
+
+	return trash, nil
+}
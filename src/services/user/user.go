@@ -1,6 +1,7 @@
 package service
 
 import (
+	"database/sql"
 	"fmt"
 
 	"github.com/beebeeoii/do-gether/db"
@@ -63,7 +64,7 @@ func RetrieveUserByUsername(username string) (interfaces.User, error) {
 	}, queryErr
 }
 
-func CreateUser(username string, hashedPassword string) (interfaces.User, error) {
+func CreateUser(ex db.Executor, username string, hashedPassword string) (interfaces.User, error) {
 	sqlCommand := "INSERT INTO users (id, username, password, friends, outgoing_req, incoming_req) VALUES ($1, $2, $3, $4, $5, $6);"
 
 	newUser := interfaces.User{
@@ -73,178 +74,195 @@ func CreateUser(username string, hashedPassword string) (interfaces.User, error)
 		Outgoing_req: []string{},
 		Incoming_req: []string{},
 	}
-	_, execErr := db.Database.Exec(sqlCommand, newUser.Id, newUser.Username, hashedPassword, pq.Array(newUser.Friends), pq.Array(newUser.Outgoing_req), pq.Array(newUser.Incoming_req))
+	_, execErr := ex.Exec(sqlCommand, newUser.Id, newUser.Username, hashedPassword, pq.Array(newUser.Friends), pq.Array(newUser.Outgoing_req), pq.Array(newUser.Incoming_req))
 
 	return newUser, execErr
 }
 
-func SendFriendRequest(senderId string, recipientId string) error {
-	recipientIncomingReq, recipientIncomingErr := RetrievePendingIncomingFriendRequest(recipientId)
-	if recipientIncomingErr != nil {
-		return recipientIncomingErr
-	}
-	senderOutgoingReq, senderOutgoingErr := RetrievePendingOutgoingFriendRequest(senderId)
-	if senderOutgoingErr != nil {
-		return senderOutgoingErr
-	}
-	if utils.Contains(recipientIncomingReq, senderId) && utils.Contains(senderOutgoingReq, recipientId) {
-		return fmt.Errorf("request is pending for response")
-	}
+func SendFriendRequest(ex db.Executor, senderId string, recipientId string) error {
+	return db.Transact(ex, func(tx db.Executor) error {
+		sender, recipient, lockErr := lockUserPair(tx, senderId, recipientId)
+		if lockErr != nil {
+			return lockErr
+		}
 
-	recipientOutgoingReq, recipientOutgoingErr := RetrievePendingOutgoingFriendRequest(recipientId)
-	if recipientOutgoingErr != nil {
-		return recipientOutgoingErr
-	}
-	senderIncomingReq, senderIncomingErr := RetrievePendingIncomingFriendRequest(senderId)
-	if senderIncomingErr != nil {
-		return senderIncomingErr
-	}
-	if utils.Contains(recipientOutgoingReq, senderId) && utils.Contains(senderIncomingReq, recipientId) {
-		return fmt.Errorf("request is pending for you to accept")
-	}
+		if utils.Contains(recipient.Incoming_req, senderId) && utils.Contains(sender.Outgoing_req, recipientId) {
+			return fmt.Errorf("request is pending for response")
+		}
 
-	recipientFriends, recipientFriendsErr := RetrieveFriends(recipientId)
-	if recipientFriendsErr != nil {
-		return recipientFriendsErr
-	}
-	senderFriends, senderFriendsErr := RetrieveFriends(senderId)
-	if senderFriendsErr != nil {
-		return senderFriendsErr
-	}
-	if utils.Contains(recipientFriends, senderId) && utils.Contains(senderFriends, recipientId) {
-		return fmt.Errorf("you are already friends")
-	}
+		if utils.Contains(recipient.Outgoing_req, senderId) && utils.Contains(sender.Incoming_req, recipientId) {
+			return fmt.Errorf("request is pending for you to accept")
+		}
+
+		if utils.Contains(recipient.Friends, senderId) && utils.Contains(sender.Friends, recipientId) {
+			return fmt.Errorf("you are already friends")
+		}
+
+		if !utils.Contains(recipient.Incoming_req, senderId) {
+			updateIncomingCommand := "UPDATE users SET incoming_req = array_append(incoming_req, $1) WHERE id = $2;"
+
+			_, updateIncomingErr := tx.Exec(updateIncomingCommand, senderId, recipientId)
+			if updateIncomingErr != nil {
+				return updateIncomingErr
+			}
+		}
 
-	if !utils.Contains(recipientIncomingReq, senderId) {
-		updateIncomingCommand := "UPDATE users SET incoming_req = array_append(incoming_req, $1) WHERE id = $2;"
+		if !utils.Contains(sender.Outgoing_req, recipientId) {
+			updateOutgoingCommand := "UPDATE users SET outgoing_req = array_append(outgoing_req, $1) WHERE id = $2;"
 
-		_, updateIncomingErr := db.Database.Exec(updateIncomingCommand, senderId, recipientId)
+			_, updateOutgoingErr := tx.Exec(updateOutgoingCommand, recipientId, senderId)
+			if updateOutgoingErr != nil {
+				return updateOutgoingErr
+			}
+		}
+
+		return nil
+	})
+}
+
+func AcceptFriendRequest(ex db.Executor, senderId string, recipientId string) error {
+	return db.Transact(ex, func(tx db.Executor) error {
+		sender, recipient, lockErr := lockUserPair(tx, senderId, recipientId)
+		if lockErr != nil {
+			return lockErr
+		}
+
+		doesRequestExist := utils.Contains(recipient.Incoming_req, senderId) && utils.Contains(sender.Outgoing_req, recipientId)
+		if !doesRequestExist {
+			return fmt.Errorf("request is non-existent")
+		}
+
+		updateIncomingCommand := "UPDATE users SET incoming_req = array_remove(incoming_req, $1) WHERE id = $2;"
+		_, updateIncomingErr := tx.Exec(updateIncomingCommand, senderId, recipientId)
 		if updateIncomingErr != nil {
 			return updateIncomingErr
 		}
-	}
-
-	if !utils.Contains(senderOutgoingReq, recipientId) {
-		updateOutgoingCommand := "UPDATE users SET outgoing_req = array_append(outgoing_req, $1) WHERE id = $2;"
 
-		_, updateOutgoingErr := db.Database.Exec(updateOutgoingCommand, recipientId, senderId)
+		updateOutgoingCommand := "UPDATE users SET outgoing_req = array_remove(outgoing_req, $1) WHERE id = $2;"
+		_, updateOutgoingErr := tx.Exec(updateOutgoingCommand, recipientId, senderId)
 		if updateOutgoingErr != nil {
 			return updateOutgoingErr
 		}
-	}
 
-	return nil
+		updateFriendsCommand := "UPDATE users SET friends = array_append(friends, $1) WHERE id = $2;"
+		_, updateFriends1Err := tx.Exec(updateFriendsCommand, recipientId, senderId)
+		if updateFriends1Err != nil {
+			return updateFriends1Err
+		}
+		_, updateFriends2Err := tx.Exec(updateFriendsCommand, senderId, recipientId)
+		if updateFriends2Err != nil {
+			return updateFriends2Err
+		}
+
+		return nil
+	})
 }
 
-func AcceptFriendRequest(senderId string, recipientId string) error {
-	recipientIncomingReq, retrieveErr := RetrievePendingIncomingFriendRequest(recipientId)
-	if retrieveErr != nil {
-		return retrieveErr
-	}
+func RemoveFriendRequest(ex db.Executor, userId string, pendingFriendId string) error {
+	return db.Transact(ex, func(tx db.Executor) error {
+		user, pendingFriend, lockErr := lockUserPair(tx, userId, pendingFriendId)
+		if lockErr != nil {
+			return lockErr
+		}
 
-	senderOutgoingReq, senderOutgoingErr := RetrievePendingOutgoingFriendRequest(senderId)
-	if senderOutgoingErr != nil {
-		return senderOutgoingErr
-	}
+		doesRequestExist := (utils.Contains(user.Incoming_req, pendingFriendId) && utils.Contains(pendingFriend.Outgoing_req, userId)) || (utils.Contains(pendingFriend.Incoming_req, userId) && utils.Contains(user.Outgoing_req, pendingFriendId))
+		if !doesRequestExist {
+			return fmt.Errorf("request is non-existent")
+		}
 
-	doesRequestExist := utils.Contains(recipientIncomingReq, senderId) && utils.Contains(senderOutgoingReq, recipientId)
-	if !doesRequestExist {
-		return fmt.Errorf("request is non-existent")
-	}
+		updateIncomingCommand := "UPDATE users SET incoming_req = array_remove(incoming_req, $1) WHERE id = $2;"
+		updateOutgoingCommand := "UPDATE users SET outgoing_req = array_remove(outgoing_req, $1) WHERE id = $2;"
 
-	updateIncomingCommand := "UPDATE users SET incoming_req = array_remove(incoming_req, $1) WHERE id = $2;"
-	_, updateIncomingErr := db.Database.Exec(updateIncomingCommand, senderId, recipientId)
-	if updateIncomingErr != nil {
-		return updateIncomingErr
-	}
+		_, update1Err := tx.Exec(updateIncomingCommand, userId, pendingFriendId)
+		if update1Err != nil {
+			return update1Err
+		}
+		_, update2Err := tx.Exec(updateIncomingCommand, pendingFriendId, userId)
+		if update2Err != nil {
+			return update2Err
+		}
+		_, update3Err := tx.Exec(updateOutgoingCommand, userId, pendingFriendId)
+		if update3Err != nil {
+			return update3Err
+		}
+		_, update4Err := tx.Exec(updateOutgoingCommand, pendingFriendId, userId)
+		if update4Err != nil {
+			return update4Err
+		}
 
-	updateOutgoingCommand := "UPDATE users SET outgoing_req = array_remove(outgoing_req, $1) WHERE id = $2;"
-	_, updateOutgoingErr := db.Database.Exec(updateOutgoingCommand, recipientId, senderId)
-	if updateOutgoingErr != nil {
-		return updateOutgoingErr
-	}
+		return nil
+	})
+}
 
-	updateFriendsCommand := "UPDATE users SET friends = array_append(friends, $1) WHERE id = $2;"
-	_, updateFriends1Err := db.Database.Exec(updateFriendsCommand, recipientId, senderId)
-	if updateFriends1Err != nil {
-		return updateFriends1Err
-	}
-	_, updateFriends2Err := db.Database.Exec(updateFriendsCommand, senderId, recipientId)
-	if updateFriends2Err != nil {
-		return updateFriends2Err
-	}
+func RemoveFriend(ex db.Executor, userId string, friendId string) error {
+	return db.Transact(ex, func(tx db.Executor) error {
+		user, _, lockErr := lockUserPair(tx, userId, friendId)
+		if lockErr != nil {
+			return lockErr
+		}
 
-	return nil
-}
+		isFriend := utils.Contains(user.Friends, friendId)
+		if !isFriend {
+			return fmt.Errorf("friend is non-existent")
+		}
 
-func RemoveFriendRequest(userId string, pendingFriendId string) error {
-	userIncomingReq, userIncomingErr := RetrievePendingIncomingFriendRequest(userId)
-	if userIncomingErr != nil {
-		return userIncomingErr
-	}
-	pendingFriendOutgoingReq, pendingFriendOutgoingErr := RetrievePendingOutgoingFriendRequest(pendingFriendId)
-	if pendingFriendOutgoingErr != nil {
-		return pendingFriendOutgoingErr
-	}
-	pendingFriendIncomingReq, pendingFriendIncomingErr := RetrievePendingIncomingFriendRequest(pendingFriendId)
-	if pendingFriendIncomingErr != nil {
-		return pendingFriendIncomingErr
-	}
-	userOutgoingReq, userOutgoingErr := RetrievePendingOutgoingFriendRequest(userId)
-	if userOutgoingErr != nil {
-		return userOutgoingErr
-	}
+		updateFriendsCommand := "UPDATE users SET friends = array_remove(friends, $1) WHERE id = $2;"
+		_, updateFriends1Err := tx.Exec(updateFriendsCommand, userId, friendId)
+		if updateFriends1Err != nil {
+			return updateFriends1Err
+		}
+		_, updateFriends2Err := tx.Exec(updateFriendsCommand, friendId, userId)
+		if updateFriends2Err != nil {
+			return updateFriends2Err
+		}
 
-	doesRequestExist := (utils.Contains(userIncomingReq, pendingFriendId) && utils.Contains(pendingFriendOutgoingReq, userId)) || (utils.Contains(pendingFriendIncomingReq, userId) && utils.Contains(userOutgoingReq, pendingFriendId))
-	if !doesRequestExist {
-		return fmt.Errorf("request is non-existent")
-	}
+		return nil
+	})
+}
 
-	updateIncomingCommand := "UPDATE users SET incoming_req = array_remove(incoming_req, $1) WHERE id = $2;"
-	updateOutgoingCommand := "UPDATE users SET outgoing_req = array_remove(outgoing_req, $1) WHERE id = $2;"
+// lockUserPair locks the rows of two users until the end of tx, always in the
+// same order so that two requests between the same users cannot deadlock, and
+// returns their friends and pending requests.
+func lockUserPair(tx db.Executor, firstId string, secondId string) (interfaces.User, interfaces.User, error) {
+	var first, second interfaces.User
+	sqlCommand := "SELECT id, username, friends, outgoing_req, incoming_req FROM users WHERE id = ANY($1) ORDER BY id FOR UPDATE"
 
-	_, update1Err := db.Database.Exec(updateIncomingCommand, userId, pendingFriendId)
-	if update1Err != nil {
-		return update1Err
-	}
-	_, update2Err := db.Database.Exec(updateIncomingCommand, pendingFriendId, userId)
-	if update2Err != nil {
-		return update2Err
-	}
-	_, update3Err := db.Database.Exec(updateOutgoingCommand, userId, pendingFriendId)
-	if update3Err != nil {
-		return update3Err
-	}
-	_, update4Err := db.Database.Exec(updateOutgoingCommand, pendingFriendId, userId)
-	if update4Err != nil {
-		return update4Err
+	rows, queryErr := tx.Query(sqlCommand, pq.Array([]string{firstId, secondId}))
+	if queryErr != nil {
+		return first, second, queryErr
 	}
+	defer rows.Close()
 
-	return nil
-}
+	for rows.Next() {
+		var user interfaces.User
+		var friends, outgoing_req, incoming_req pq.StringArray
 
-func RemoveFriend(userId string, friendId string) error {
-	friends, friendsErr := RetrieveFriends(userId)
-	if friendsErr != nil {
-		return friendsErr
-	}
+		scanErr := rows.Scan(&user.Id, &user.Username, &friends, &outgoing_req, &incoming_req)
+		if scanErr != nil {
+			return first, second, scanErr
+		}
+		user.Friends = friends
+		user.Outgoing_req = outgoing_req
+		user.Incoming_req = incoming_req
 
-	isFriend := utils.Contains(friends, friendId)
-	if !isFriend {
-		return fmt.Errorf("friend is non-existent")
+		if user.Id == firstId {
+			first = user
+		}
+		if user.Id == secondId {
+			second = user
+		}
 	}
 
-	updateFriendsCommand := "UPDATE users SET friends = array_remove(friends, $1) WHERE id = $2;"
-	_, updateFriends1Err := db.Database.Exec(updateFriendsCommand, userId, friendId)
-	if updateFriends1Err != nil {
-		return updateFriends1Err
+	rowsErr := rows.Err()
+	if rowsErr != nil {
+		return first, second, rowsErr
 	}
-	_, updateFriends2Err := db.Database.Exec(updateFriendsCommand, friendId, userId)
-	if updateFriends2Err != nil {
-		return updateFriends2Err
+
+	if first.Id == "" || second.Id == "" {
+		return first, second, sql.ErrNoRows
 	}
 
-	return nil
+	return first, second, nil
 }
 
 func RetrieveFriends(userId string) ([]string, error) {
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var validKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+type LocalStorage struct {
+	root string
+}
+
+func NewLocalStorage(root string) (*LocalStorage, error) {
+	mkdirErr := os.MkdirAll(root, 0o750)
+	if mkdirErr != nil {
+		return nil, mkdirErr
+	}
+
+	return &LocalStorage{root: root}, nil
+}
+
+func (storage *LocalStorage) path(key string) (string, error) {
+	if !validKey.MatchString(key) {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+
+	return filepath.Join(storage.root, key), nil
+}
+
+// Put writes to a temporary file first so that a failed or partial upload
+// never replaces an existing object.
+func (storage *LocalStorage) Put(key string, content io.Reader, size int64, mimeType string) error {
+	path, pathErr := storage.path(key)
+	if pathErr != nil {
+		return pathErr
+	}
+
+	tempFile, createErr := os.CreateTemp(storage.root, ".upload-*")
+	if createErr != nil {
+		return createErr
+	}
+	defer os.Remove(tempFile.Name())
+
+	written, copyErr := io.Copy(tempFile, content)
+	closeErr := tempFile.Close()
+	if copyErr != nil {
+		return copyErr
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	if written != size {
+		return fmt.Errorf("expected %d bytes but wrote %d", size, written)
+	}
+
+	return os.Rename(tempFile.Name(), path)
+}
+
+func (storage *LocalStorage) Get(key string) (io.ReadCloser, error) {
+	path, pathErr := storage.path(key)
+	if pathErr != nil {
+		return nil, pathErr
+	}
+
+	return os.Open(path)
+}
+
+func (storage *LocalStorage) Delete(key string) error {
+	path, pathErr := storage.path(key)
+	if pathErr != nil {
+		return pathErr
+	}
+
+	removeErr := os.Remove(path)
+	if removeErr != nil && !os.IsNotExist(removeErr) {
+		return removeErr
+	}
+
+	return nil
+}
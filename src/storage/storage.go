@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	STORAGE_DRIVER_LOCAL = "local"
+	DEFAULT_LOCAL_ROOT   = "./attachments"
+)
+
+type Backend interface {
+	Put(key string, content io.Reader, size int64, mimeType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+var Storage Backend
+
+func Init() (err error) {
+	STORAGE_DRIVER := os.Getenv("STORAGE_DRIVER")
+	if STORAGE_DRIVER == "" {
+		STORAGE_DRIVER = STORAGE_DRIVER_LOCAL
+	}
+
+	switch STORAGE_DRIVER {
+	case STORAGE_DRIVER_LOCAL:
+		STORAGE_LOCAL_ROOT := os.Getenv("STORAGE_LOCAL_ROOT")
+		if STORAGE_LOCAL_ROOT == "" {
+			STORAGE_LOCAL_ROOT = DEFAULT_LOCAL_ROOT
+		}
+
+		localStorage, localStorageErr := NewLocalStorage(STORAGE_LOCAL_ROOT)
+		if localStorageErr != nil {
+			return localStorageErr
+		}
+		Storage = localStorage
+	default:
+		return fmt.Errorf("unsupported storage driver %q", STORAGE_DRIVER)
+	}
+
+	return nil
+}